@@ -0,0 +1,103 @@
+package graphql
+
+import (
+	"encoding/json"
+	"errors"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/lascyb/struct-to-graphql/core"
+)
+
+// Bind 为查询中的变量赋值并返回最终的 variables JSON。
+// values 的键可以是变量名（"id" 或 "$id"），也可以是 Variable.Paths 中记录的字段路径（如 "items/nodes"，
+// 该路径只对应一个变量时有效）；MarshalWithVars 得到的 Values 作为初始值，可被 values 覆盖。
+// 未提供值的变量有默认值时使用默认值，非空类型（"!"）且无默认值时报错，Values 中的 null 同样受非空类型约束；未知的键同样报错
+func (g *Graphql) Bind(values map[string]any) ([]byte, error) {
+	if g == nil {
+		return nil, core.NewError(core.ErrNilInput, "", "graphql cannot be nil")
+	}
+	byName := make(map[string]*core.Variable, len(g.Variables))
+	byPath := make(map[string][]*core.Variable)
+	for _, v := range g.Variables {
+		byName[strings.TrimPrefix(v.Name, "$")] = v
+		for _, path := range v.Paths {
+			if !slices.Contains(byPath[path], v) {
+				byPath[path] = append(byPath[path], v)
+			}
+		}
+	}
+
+	var errs []error
+	result := make(map[string]any, len(g.Variables))
+	maps.Copy(result, g.Values)
+	assigned := make(map[string]string) // 变量名 => 赋值时使用的键
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		name := strings.TrimPrefix(key, "$")
+		if _, ok := byName[name]; !ok {
+			candidates := byPath[key]
+			switch len(candidates) {
+			case 0:
+				errs = append(errs, core.NewError(core.ErrUnknownVariable, key, "unknown variable or path %s", key))
+				continue
+			case 1:
+				name = strings.TrimPrefix(candidates[0].Name, "$")
+			default:
+				names := make([]string, len(candidates))
+				for i, v := range candidates {
+					names[i] = v.Name
+				}
+				errs = append(errs, core.NewError(core.ErrAmbiguousPath, key, "path %s matches variables %s; bind them by name", key, strings.Join(names, ", ")))
+				continue
+			}
+		}
+		if previous, ok := assigned[name]; ok {
+			errs = append(errs, core.VariableError(byName[name], core.ErrDuplicateValue, "variable $%s is assigned twice, by %s and %s", name, previous, key))
+			continue
+		}
+		assigned[name] = key
+		if isNull(values[key]) && strings.HasSuffix(byName[name].Type, "!") {
+			errs = append(errs, core.VariableError(byName[name], core.ErrNullValue, "variable %s of type %s cannot be null", byName[name].Name, byName[name].Type))
+			continue
+		}
+		result[name] = values[key]
+	}
+	for _, v := range g.Variables {
+		name := strings.TrimPrefix(v.Name, "$")
+		if assigned[name] != "" {
+			continue
+		}
+		value, fromVars := result[name]
+		nonNull := strings.HasSuffix(v.Type, "!")
+		switch {
+		case fromVars && (!nonNull || !isNull(value)):
+		case v.HasDefault:
+			// 未提供值，或变量结构体中非空类型变量的值为 null（如 nil 指针）时使用默认值
+			result[name] = v.DefaultValue
+		case !nonNull:
+		case fromVars:
+			delete(result, name)
+			errs = append(errs, core.VariableError(v, core.ErrNullValue, "variable %s of type %s cannot be null", v.Name, v.Type))
+		default:
+			errs = append(errs, core.VariableError(v, core.ErrMissingValue, "variable %s of type %s requires a value", v.Name, v.Type))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return json.Marshal(result)
+}
+
+// isNull 判断值是否会被编码为 JSON null：nil 以及 nil 指针、map、切片、接口
+func isNull(v any) bool {
+	if v == nil {
+		return true
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		return rv.IsNil()
+	}
+	return false
+}
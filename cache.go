@@ -0,0 +1,163 @@
+package graphql
+
+import (
+	"container/list"
+	"maps"
+	"reflect"
+	"sync"
+
+	"github.com/lascyb/struct-to-graphql/core"
+)
+
+// DefaultCacheSize 缓存的生成结果数量上限的默认值
+const DefaultCacheSize = 1024
+
+// plans 按类型与选项缓存的生成结果，超过上限时淘汰最久未使用的结果。
+// 缓存在进程内全局共享；条目在被淘汰前一直有效，不会因为 Schema 等选项的值在之后被修改而失效
+var plans = &planCache{size: DefaultCacheSize, entries: make(map[planKey]*list.Element), lru: list.New()}
+
+// planCache 有容量上限的 LRU 缓存：planKey => *plan
+type planCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[planKey]*list.Element // 元素的值为 *planEntry
+	lru     *list.List                // 最近使用的在前
+}
+
+type planEntry struct {
+	key  planKey
+	plan *plan
+}
+
+// load 返回 key 对应的缓存项，不存在时创建并可能淘汰最久未使用的缓存项；容量为 0 时返回 nil
+func (c *planCache) load(key planKey) *plan {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.size <= 0 {
+		return nil
+	}
+	if elem, ok := c.entries[key]; ok {
+		c.lru.MoveToFront(elem)
+		return elem.Value.(*planEntry).plan
+	}
+	p := new(plan)
+	c.entries[key] = c.lru.PushFront(&planEntry{key: key, plan: p})
+	c.evict()
+	return p
+}
+
+// evict 淘汰超出容量的最久未使用的缓存项，调用方需持有锁
+func (c *planCache) evict() {
+	for c.lru.Len() > c.size {
+		elem := c.lru.Back()
+		c.lru.Remove(elem)
+		delete(c.entries, elem.Value.(*planEntry).key)
+	}
+}
+
+// SetCacheSize 设置缓存的生成结果数量上限（默认 DefaultCacheSize），超出的缓存项立即被淘汰；
+// 传入 0 清空并关闭缓存。按请求或租户创建 Schema 时每个 Schema 值都会占用独立的缓存项，可按需调整上限
+func SetCacheSize(n int) {
+	plans.mu.Lock()
+	defer plans.mu.Unlock()
+	plans.size = max(n, 0)
+	plans.evict()
+}
+
+// planKey 缓存键：结构体类型与影响生成结果的选项（indent 为实际使用的缩进，见 Options.IndentUnit）；
+// Schema 按值比较（*schema.Schema 即按指针比较），修改已使用过的 Schema 后应使用新的 Schema 值或 Options.DisableCache
+type planKey struct {
+	typ          reflect.Type
+	indent       string
+	schema       core.Schema
+	blockStrings bool
+	operation    string
+	allErrors    bool
+	maxDepth     int
+	fragments    core.FragmentPolicy
+}
+
+// plan 某个类型在一组选项下的生成结果，只计算一次；返回给调用方的是 graphql 的深拷贝，调用方可以自由修改
+type plan struct {
+	once    sync.Once
+	graphql *Graphql
+	errs    []error // Options.AllErrors 为 true 时收集的解析与生成错误
+	err     error   // 在第一个错误处返回时的错误
+}
+
+// newPlanKey 返回缓存键，选项无法可靠地比较（自定义 VariableNamer 或 FragmentNamer、不可比较的 Schema）或禁用缓存时返回 false
+func newPlanKey(typ reflect.Type, opts Options) (planKey, bool) {
+	if opts.DisableCache || opts.VariableNamer != nil || opts.FragmentNamer != nil {
+		return planKey{}, false
+	}
+	if opts.Schema != nil && !reflect.TypeOf(opts.Schema).Comparable() {
+		return planKey{}, false
+	}
+	return planKey{
+		typ:          typ,
+		indent:       opts.IndentUnit(),
+		schema:       opts.Schema,
+		blockStrings: opts.BlockStrings,
+		operation:    opts.Operation,
+		allErrors:    opts.AllErrors,
+		maxDepth:     opts.MaxDepth,
+		fragments:    opts.Fragments,
+	}, true
+}
+
+// loadPlan 返回类型 typ 在 opts 下的生成结果，可缓存时并发调用只计算一次
+func loadPlan(typ reflect.Type, opts Options) *plan {
+	key, ok := newPlanKey(typ, opts)
+	if !ok {
+		p := new(plan)
+		p.compile(typ, opts)
+		return p
+	}
+	p := plans.load(key)
+	if p == nil {
+		p = new(plan)
+	}
+	p.once.Do(func() { p.compile(typ, opts) })
+	return p
+}
+
+// compile 解析并生成 typ 的查询主体、变量与 Fragment
+func (p *plan) compile(typ reflect.Type, opts Options) {
+	parser := core.NewParserWithOptions(opts)
+	typeParser, err := parser.ParseType(typ)
+	if err != nil {
+		p.err = joinErrors(append(parser.Errors(), err))
+		return
+	}
+	builder := core.NewBuilderWithOptions(opts)
+	body, err := builder.Build(typeParser)
+	if err != nil {
+		p.err = err
+		return
+	}
+	p.errs = append(parser.Errors(), builder.Errors()...)
+	p.graphql = &Graphql{
+		Body:      body,
+		Variables: builder.Variables(),
+		Fragments: builder.Fragments(),
+
+		typeParser: typeParser,
+	}
+}
+
+// clone 深拷贝变量、Fragment 与变量值；解析结果（typeParser）只在包内只读使用，可以共享，TypeParser 返回的是副本自己的深拷贝
+func (g *Graphql) clone() *Graphql {
+	clone := *g
+	clone.Variables = make([]*core.Variable, len(g.Variables))
+	for i, v := range g.Variables {
+		clone.Variables[i] = v.Clone()
+	}
+	clone.Fragments = make([]*core.Fragment, len(g.Fragments))
+	for i, f := range g.Fragments {
+		fragment := *f
+		clone.Fragments[i] = &fragment
+	}
+	clone.Values = maps.Clone(g.Values)
+	clone.owned = new(ownedTypeParser)
+	return &clone
+}
@@ -0,0 +1,85 @@
+// Package client 基于结构体定义执行 GraphQL 操作：用 graphql.Marshal 生成文档、发送请求，
+// 并把响应解码回生成文档所用的同一个结构体类型
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	graphql "github.com/lascyb/struct-to-graphql"
+	"github.com/lascyb/struct-to-graphql/core"
+)
+
+// Client GraphQL 服务端点配置，零值字段使用默认行为，可在多个 goroutine 中共享
+type Client struct {
+	Endpoint    string         // GraphQL HTTP 端点，如 "https://example.com/graphql"
+	WSEndpoint  string         // 订阅使用的 WebSocket 端点，为空时由 Endpoint 推导（http→ws，https→wss）
+	Header      http.Header    // 每个请求（含 WebSocket 握手）附带的请求头
+	HTTPClient  *http.Client   // 为空时使用 http.DefaultClient
+	InitPayload map[string]any // graphql-transport-ws 中 connection_init 消息的 payload，常用于鉴权
+	Schema      graphql.Schema // 设置后按 schema 推断未声明类型的变量，如 *schema.Schema
+}
+
+// New 创建指向 endpoint 的客户端
+func New(endpoint string) *Client {
+	return &Client{Endpoint: endpoint}
+}
+
+// wsEndpoint 返回订阅使用的 WebSocket 地址
+func (c *Client) wsEndpoint() string {
+	if c.WSEndpoint != "" {
+		return c.WSEndpoint
+	}
+	switch {
+	case strings.HasPrefix(c.Endpoint, "https://"):
+		return "wss://" + strings.TrimPrefix(c.Endpoint, "https://")
+	case strings.HasPrefix(c.Endpoint, "http://"):
+		return "ws://" + strings.TrimPrefix(c.Endpoint, "http://")
+	}
+	return c.Endpoint
+}
+
+// operation 由 T 生成的操作文档，嵌入的 graphql.Operation 同时用于解码响应
+type operation[T any] struct {
+	*graphql.Operation[T]
+	document string
+	name     string
+}
+
+// buildOperation 以 T 的结构体定义生成完整操作文档
+func buildOperation[T any](c *Client, kind string) (*operation[T], error) {
+	exec, err := graphql.MarshalTypeWithOptions[T](graphql.Options{Schema: c.Schema, Operation: kind})
+	if err != nil {
+		return nil, err
+	}
+	name := operationName[T]()
+	var document string
+	switch kind {
+	case "query":
+		document, err = exec.Query(name)
+	case "mutation":
+		document, err = exec.Mutation(name)
+	case "subscription":
+		document, err = exec.Subscription(name)
+	default:
+		return nil, fmt.Errorf("unsupported operation type %q", kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &operation[T]{Operation: exec, document: document, name: name}, nil
+}
+
+// operationName 使用 T 的 Go 类型名作为操作名，匿名结构体或名称不是合法 GraphQL Name 时（如泛型实例）返回空串
+func operationName[T any]() string {
+	typ := reflect.TypeFor[T]()
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if name := typ.Name(); core.IsGraphQLName(name) {
+		return name
+	}
+	return ""
+}
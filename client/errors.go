@@ -0,0 +1,56 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Location 错误在文档中的位置
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Error GraphQL 响应 errors 中的单条错误
+type Error struct {
+	Message    string         `json:"message"`
+	Locations  []Location     `json:"locations,omitempty"`
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+func (e *Error) Error() string {
+	if len(e.Path) == 0 {
+		return "graphql: " + e.Message
+	}
+	path := make([]string, 0, len(e.Path))
+	for _, p := range e.Path {
+		path = append(path, fmt.Sprint(p))
+	}
+	return fmt.Sprintf("graphql: %s (path: %s)", e.Message, strings.Join(path, "."))
+}
+
+// Code 返回 extensions.code（常见的错误分类约定），不存在时返回空串
+func (e *Error) Code() string {
+	code, _ := e.Extensions["code"].(string)
+	return code
+}
+
+// Errors 一次响应中返回的全部错误，可通过 errors.As 取出单条 *Error
+type Errors []*Error
+
+func (e Errors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e Errors) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, err := range e {
+		errs = append(errs, err)
+	}
+	return errs
+}
@@ -0,0 +1,118 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// maxErrorBody HTTPError 中保留的响应体长度上限
+const maxErrorBody = 4 << 10
+
+// HTTPError 服务端返回非 2xx 状态码且响应体不是 GraphQL 结果时的错误
+type HTTPError struct {
+	StatusCode int
+	Status     string
+	Body       []byte // 响应体（截断至 4KB）
+}
+
+func (e *HTTPError) Error() string {
+	if len(e.Body) == 0 {
+		return "graphql: unexpected HTTP status " + e.Status
+	}
+	return fmt.Sprintf("graphql: unexpected HTTP status %s: %s", e.Status, e.Body)
+}
+
+// Result 一次执行的完整结果：解码后的数据与响应顶层的 extensions（如查询成本、追踪信息）
+type Result[T any] struct {
+	Data       T
+	Extensions map[string]any
+}
+
+// Execute 以 T 的结构体定义生成 query 操作，按 {query, variables, operationName} 发送 POST 请求，并将 data 解码为 T。
+// 响应中的 errors 以 Errors 返回（同时返回已解码的部分数据），非 2xx 且无法解析为 GraphQL 结果时返回 *HTTPError。
+func Execute[T any](ctx context.Context, c *Client, vars map[string]any) (T, error) {
+	result, err := do[T](ctx, c, "query", vars)
+	return result.Data, err
+}
+
+// Mutate 与 Execute 相同，但生成 mutation 操作
+func Mutate[T any](ctx context.Context, c *Client, vars map[string]any) (T, error) {
+	result, err := do[T](ctx, c, "mutation", vars)
+	return result.Data, err
+}
+
+// ExecuteResult 与 Execute 相同，但同时返回响应顶层的 extensions；响应携带 errors 时仍返回部分数据与 extensions
+func ExecuteResult[T any](ctx context.Context, c *Client, vars map[string]any) (Result[T], error) {
+	return do[T](ctx, c, "query", vars)
+}
+
+// MutateResult 与 ExecuteResult 相同，但生成 mutation 操作
+func MutateResult[T any](ctx context.Context, c *Client, vars map[string]any) (Result[T], error) {
+	return do[T](ctx, c, "mutation", vars)
+}
+
+func do[T any](ctx context.Context, c *Client, operation string, vars map[string]any) (Result[T], error) {
+	op, err := buildOperation[T](c, operation)
+	if err != nil {
+		return Result[T]{}, err
+	}
+	result, err := c.post(ctx, payload{Query: op.document, Variables: vars, OperationName: op.name})
+	if err != nil {
+		return Result[T]{}, err
+	}
+	data, err := decodeData[T](op, result)
+	return Result[T]{Data: data, Extensions: result.Extensions}, err
+}
+
+// post 发送 GraphQL 请求并解析执行结果
+func (c *Client) post(ctx context.Context, body payload) (*executionResult, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.Endpoint, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range c.Header {
+		req.Header[k] = append([]string(nil), v...)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "application/graphql-response+json, application/json")
+	}
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	result := new(executionResult)
+	decodeErr := json.Unmarshal(raw, result)
+	// application/graphql-response+json 在请求错误时会返回 4xx 与 errors，此时优先返回 GraphQL 错误
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		if decodeErr == nil && len(result.Errors) > 0 {
+			return result, nil
+		}
+		if len(raw) > maxErrorBody {
+			raw = raw[:maxErrorBody]
+		}
+		return nil, &HTTPError{StatusCode: resp.StatusCode, Status: resp.Status, Body: raw}
+	}
+	if decodeErr != nil {
+		return nil, fmt.Errorf("graphql: invalid response: %w", decodeErr)
+	}
+	return result, nil
+}
@@ -0,0 +1,204 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+
+	"github.com/lascyb/struct-to-graphql/internal/websocket"
+)
+
+// Subprotocol graphql-transport-ws 协议在 WebSocket 握手中使用的子协议名
+const Subprotocol = "graphql-transport-ws"
+
+// subscriptionID 每个连接只承载一个订阅，使用固定 id
+const subscriptionID = "1"
+
+// wsMessage graphql-transport-ws 协议消息
+type wsMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// payload subscribe 消息与 HTTP 请求共用的请求体
+type payload struct {
+	Query         string         `json:"query"`
+	Variables     map[string]any `json:"variables,omitempty"`
+	OperationName string         `json:"operationName,omitempty"`
+}
+
+// executionResult next 消息与 HTTP 响应共用的执行结果
+type executionResult struct {
+	Data       json.RawMessage `json:"data"`
+	Errors     Errors          `json:"errors,omitempty"`
+	Extensions map[string]any  `json:"extensions,omitempty"`
+}
+
+// Subscribe 以 T 的结构体定义生成 subscription 操作，通过 graphql-transport-ws 协议订阅，
+// 并把每条 next 消息的 data 解码为 T 依次产出。
+// 携带 errors 的 next 消息同时产出已解码的数据与 Errors；服务端发送 error 消息、连接失败或 ctx 结束时产出错误并终止；
+// 服务端发送 complete 时正常结束。提前退出循环会向服务端发送 complete 并关闭连接。
+func Subscribe[T any](ctx context.Context, c *Client, vars map[string]any) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		op, err := buildOperation[T](c, "subscription")
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		conn, err := c.connect(ctx)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		defer conn.Close()
+		stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+		defer stop()
+
+		fail := func(err error) {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				err = ctxErr
+			}
+			yield(zero, err)
+		}
+		if err := writeMessage(conn, subscriptionID, "subscribe", payload{Query: op.document, Variables: vars, OperationName: op.name}); err != nil {
+			fail(err)
+			return
+		}
+		for {
+			msg, err := readMessage(conn)
+			if err != nil {
+				fail(err)
+				return
+			}
+			// ping / pong 之外的消息都带订阅 id，其他 id 的消息不属于本订阅，直接丢弃
+			if msg.Type != "ping" && msg.Type != "pong" && msg.ID != subscriptionID {
+				continue
+			}
+			switch msg.Type {
+			case "ping":
+				if err := writeMessage(conn, "", "pong", nil); err != nil {
+					fail(err)
+					return
+				}
+			case "next":
+				var result executionResult
+				if err := json.Unmarshal(msg.Payload, &result); err != nil {
+					fail(fmt.Errorf("graphql-transport-ws: invalid next payload: %w", err))
+					return
+				}
+				value, err := decodeData[T](op, &result)
+				if !yield(value, err) {
+					_ = writeMessage(conn, subscriptionID, "complete", nil)
+					return
+				}
+			case "error":
+				var errs Errors
+				if err := json.Unmarshal(msg.Payload, &errs); err != nil {
+					fail(fmt.Errorf("graphql-transport-ws: invalid error payload: %w", err))
+					return
+				}
+				yield(zero, errs)
+				return
+			case "complete":
+				return
+			}
+		}
+	}
+}
+
+// connect 建立 WebSocket 连接并完成 connection_init / connection_ack 握手
+func (c *Client) connect(ctx context.Context) (*websocket.Conn, error) {
+	conn, err := websocket.Dial(ctx, c.wsEndpoint(), c.Header, Subprotocol)
+	if err != nil {
+		return nil, err
+	}
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+
+	var initPayload any
+	if c.InitPayload != nil {
+		initPayload = c.InitPayload
+	}
+	if err := writeMessage(conn, "", "connection_init", initPayload); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	for {
+		msg, err := readMessage(conn)
+		if err != nil {
+			_ = conn.Close()
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			return nil, err
+		}
+		switch msg.Type {
+		case "connection_ack":
+			return conn, nil
+		case "ping":
+			if err := writeMessage(conn, "", "pong", nil); err != nil {
+				_ = conn.Close()
+				return nil, err
+			}
+		case "pong":
+		default:
+			_ = conn.Close()
+			return nil, fmt.Errorf("graphql-transport-ws: unexpected message %q before connection_ack", msg.Type)
+		}
+	}
+}
+
+// decodeData 按生成文档时的响应键将执行结果中的 data 解码为 T，结果携带 errors 时一并返回；
+// 部分失败的结果（如非空字段为 null）解码出错时，解码错误与 errors 合并返回
+func decodeData[T any](op *operation[T], result *executionResult) (T, error) {
+	var value T
+	if len(result.Data) > 0 && string(result.Data) != "null" {
+		var err error
+		if value, err = op.Decode(result.Data); err != nil {
+			if len(result.Errors) > 0 {
+				return value, errors.Join(err, result.Errors)
+			}
+			return value, err
+		}
+	}
+	if len(result.Errors) > 0 {
+		return value, result.Errors
+	}
+	return value, nil
+}
+
+func writeMessage(conn *websocket.Conn, id, typ string, body any) error {
+	msg := wsMessage{ID: id, Type: typ}
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		msg.Payload = raw
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return conn.WriteMessage(websocket.TextMessage, data)
+}
+
+func readMessage(conn *websocket.Conn) (*wsMessage, error) {
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		var closeErr *websocket.CloseError
+		if errors.As(err, &closeErr) {
+			return nil, fmt.Errorf("graphql-transport-ws: connection closed: %w", err)
+		}
+		return nil, err
+	}
+	msg := new(wsMessage)
+	if err := json.Unmarshal(data, msg); err != nil {
+		return nil, fmt.Errorf("graphql-transport-ws: invalid message: %w", err)
+	}
+	return msg, nil
+}
@@ -3,14 +3,29 @@ package core
 import (
 	"fmt"
 	"reflect"
+	"slices"
 	"strings"
 	"unicode"
 )
 
 type Builder struct {
-	FragmentMap  map[reflect.Type]*Fragment // Fragment 映射，用于去重
-	VariableMap  map[string]*Variable       // 变量映射，用于去重
-	currentPaths []string
+	FragmentMap   map[reflect.Type]*Fragment // Fragment 映射，用于去重
+	VariableMap   map[string]*Variable       // 变量映射，用于去重
+	currentPaths  []string
+	fragmentOrder []reflect.Type // Fragment 生成顺序：被依赖的 Fragment 总是先于依赖它的 Fragment 完成
+	variableOrder []string       // 变量首次出现的顺序
+	options       Options
+	indents       []string      // 按层级缓存的缩进字符串
+	parentType    string        // 当前选择集在 schema 中的类型名，仅在设置 Options.Schema 时用于推断变量类型
+	root          string        // 根结构体类型名，用于错误与变量的 Go 字段路径
+	segments      []pathSegment // 与 currentPaths 一一对应的字段路径，用于错误定位
+	errs          []error       // Options.AllErrors 为 true 时收集的错误
+	// fragmentPlans 生成前由 planFragments 决定的 Fragment，按类型索引
+	fragmentPlans map[reflect.Type]*fragmentSpec
+	// fragmentNames 已分配的 Fragment 名称，用于避免重名
+	fragmentNames map[string]reflect.Type
+	// expanding 正在生成选择集的类型（含正在生成定义的 Fragment）及其嵌套层数
+	expanding map[reflect.Type]int
 }
 
 // Fragment GraphQL Fragment
@@ -27,21 +42,82 @@ type Variable struct {
 	Type         string      // 变量类型（如 Int、Int!、String、String!）
 	HasDefault   bool        // 是否有默认值
 	DefaultValue interface{} // 默认值，用于变量定义中的 " = value"
+	GoPaths      []string    // 变量所在字段的 Go 字段路径（如 "ProductQuery.Items"），与 Paths 一一对应
+	inferred     bool        // Type 是否由 schema 推断得到（显式声明的类型会覆盖推断结果）
+	graphQLPath  string      // 变量首次出现的字段的 GraphQL 响应路径，用于错误定位
 }
 
+// Clone 返回变量的副本，Paths 与 GoPaths 不与原变量共享
+func (v *Variable) Clone() *Variable {
+	clone := *v
+	clone.Paths = slices.Clone(v.Paths)
+	clone.GoPaths = slices.Clone(v.GoPaths)
+	return &clone
+}
+
+// NewBuilder 使用默认选项创建 Builder，缩进为 SetIndent 设置的值（默认两个空格）
 func NewBuilder() *Builder {
+	return NewBuilderWithOptions(DefaultIndentOptions())
+}
+
+// NewBuilderWithOptions 使用指定选项创建 Builder，选项只作用于该 Builder，可安全地并发使用多个 Builder
+func NewBuilderWithOptions(options Options) *Builder {
 	return &Builder{
-		FragmentMap:  make(map[reflect.Type]*Fragment),
-		VariableMap:  make(map[string]*Variable),
-		currentPaths: []string{},
+		FragmentMap:   make(map[reflect.Type]*Fragment),
+		VariableMap:   make(map[string]*Variable),
+		currentPaths:  []string{},
+		options:       options.withDefaults(),
+		fragmentPlans: make(map[reflect.Type]*fragmentSpec),
+		fragmentNames: make(map[string]reflect.Type),
+		expanding:     make(map[reflect.Type]int),
+	}
+}
+
+// Variables 按首次出现的顺序返回变量列表
+func (g *Builder) Variables() []*Variable {
+	variables := make([]*Variable, 0, len(g.variableOrder))
+	for _, name := range g.variableOrder {
+		variables = append(variables, g.VariableMap[name])
+	}
+	return variables
+}
+
+// Errors 返回 Options.AllErrors 为 true 时收集的错误，按发现的顺序排列
+func (g *Builder) Errors() []error {
+	return g.errs
+}
+
+// report Options.AllErrors 为 true 时记录错误并返回 nil 以便继续生成，否则原样返回错误
+func (g *Builder) report(err error) error {
+	if !g.options.AllErrors {
+		return err
+	}
+	g.errs = append(g.errs, err)
+	return nil
+}
+
+// Fragments 按依赖顺序返回 Fragment 列表（被引用者在前）
+func (g *Builder) Fragments() []*Fragment {
+	fragments := make([]*Fragment, 0, len(g.fragmentOrder))
+	for _, typ := range g.fragmentOrder {
+		fragments = append(fragments, g.FragmentMap[typ])
 	}
+	return fragments
 }
 
 func (g *Builder) Build(typeParser *TypeParser) (string, error) {
 	if typeParser != nil {
-		return g.buildSelectionSet(typeParser, false, typeParser.Union, 0)
+		g.root = typeName(typeParser.source)
+		if g.options.Schema != nil && g.options.Operation != "" {
+			g.parentType = g.options.Schema.RootTypeName(g.options.Operation)
+		}
+		// 先遍历整个类型图决定 Fragment 及其名称，生成时每次引用都输出为展开语法
+		if err := g.planFragments(typeParser); err != nil {
+			return "", err
+		}
+		return g.buildSelectionSet(typeParser, nil, false, typeParser.Union, 0)
 	}
-	return "", fmt.Errorf("struct to parse cannot be nil")
+	return "", NewError(ErrNilInput, "", "struct to parse cannot be nil")
 }
 
 // buildSelectionSet 递归生成 GraphQL 类型定义字符串
@@ -49,136 +125,227 @@ func (g *Builder) Build(typeParser *TypeParser) (string, error) {
 // typeParser: 类型解析器，包含字段列表、联合类型标识和重用次数等信息
 // inlineType: 是否为内联类型，true 表示该类型是匿名字段或标记为 inline 的字段，字段名会被省略
 // isUnionSubType: 是否为联合类型的子类型，true 表示当前正在处理联合类型的某个具体类型分支
+// field: 选择集所属的字段，根类型为 nil；其 fragment / nofragment 标记决定是否封装为 Fragment
 // level: 缩进层级，用于格式化输出，0 表示顶级，每递归一层递增
 // path: 当前字段路径，用于参数变量名生成
 // 返回: GraphQL 类型定义字符串，格式如 "{ field1 { nestedField } field2 }" 或 "... on TypeName { field }"
-func (g *Builder) buildSelectionSet(typeParser *TypeParser, inlineType, isUnionSubType bool, level uint) (string, error) {
+func (g *Builder) buildSelectionSet(typeParser *TypeParser, field *FieldParser, inlineType, isUnionSubType bool, level uint) (string, error) {
 	if typeParser == nil {
 		return "", nil
 	}
-	if typeParser.Reused > 1 && typeParser.source.Name() != "" {
-		if fragment, ok := g.FragmentMap[typeParser.source]; ok {
-			if inlineType && !isUnionSubType {
-				return fmt.Sprintf("\n%s...%s", indentWithLevel(level+1), fragment.Name), nil
-			}
-			return fmt.Sprintf("{ ...%s }", fragment.Name), nil
-		}
-		if !inlineType {
-			level = 0
+	spec := g.fragmentFor(typeParser, field)
+	spreadLevel := level
+	if spec != nil {
+		if _, ok := g.FragmentMap[typeParser.source]; ok {
+			return g.fragmentSpread(spec.name, inlineType && !isUnionSubType, spreadLevel), nil
 		}
+		// Fragment 定义从第 0 层开始缩进，定义中引用的其他 Fragment 先于该定义生成
+		level = 0
 	}
+	g.expanding[typeParser.source]++
+	defer func() { g.expanding[typeParser.source]-- }()
 	buf := new(strings.Builder)
 
-	// 非内联类型或联合子类型需要添加花括号包裹字段
-	if !inlineType || isUnionSubType {
+	// 非内联类型、联合子类型或需要封装为 Fragment 时添加花括号包裹字段
+	if !inlineType || isUnionSubType || spec != nil {
 		buf.WriteString("{")
 	}
 	// 遍历所有字段，递归构建 GraphQL 查询字符串
 	currentPathsCount := len(g.currentPaths)
+	parentType := g.parentType
 	defer func() {
 		// 使用 defer 确保路径栈始终被恢复，即使在异常情况下也不会导致状态污染
 		g.currentPaths = g.currentPaths[:currentPathsCount]
+		g.segments = g.segments[:currentPathsCount]
+		g.parentType = parentType
 	}()
 
 	for _, field := range typeParser.Fields {
 		g.currentPaths = append(g.currentPaths[:currentPathsCount], field.FieldName)
-		// 处理联合类型：使用 GraphQL 的 inline fragment 语法 "... on TypeName"
-		if typeParser.Union {
+		g.segments = append(g.segments[:currentPathsCount], field.pathSegment())
+		g.parentType = parentType
+		// 处理联合类型及接口类型的分支：使用 GraphQL 的 inline fragment 语法 "... on TypeName"
+		// 接口类型的普通字段（含 __typename）作为公共字段，按普通字段处理
+		if typeParser.Union || (typeParser.Interface && field.Inline) {
+			// 先校验分支，通过后才写入，收集模式下跳过的分支不会留下不完整的 "... on"
+			if field.FieldName != "__typename" {
+				var invalid *FieldError
+				switch {
+				case field.TypeParser == nil:
+					invalid = g.fieldError(ErrInvalidUnion, field.FieldName, "union member %s of %s should be a struct type", field.FieldName, typeParser.source.String())
+				case field.TypeName == "":
+					invalid = g.fieldError(ErrInvalidUnion, field.FieldName, "anonymous struct types are not supported as union members")
+				}
+				if invalid != nil {
+					if err := g.report(invalid); err != nil {
+						return "", err
+					}
+					continue
+				}
+			}
+			directives, err := g.buildDirectives(field)
+			if err != nil {
+				return "", err
+			}
 			buf.WriteString("\n")
-			buf.WriteString(indentWithLevel(level + 1))
+			buf.WriteString(g.indentWithLevel(level + 1))
 			// __typename 字段直接输出，用于类型判断
 			if field.FieldName == "__typename" {
 				buf.WriteString(field.FieldName)
-			} else if field.TypeParser != nil {
-				// 联合类型的其他字段使用 "... on TypeName" 语法
-				buf.WriteString("... on ")
-				if field.TypeName == "" {
-					return "", fmt.Errorf("anonymous struct types are not supported for field [%s] in union types", field.FieldName)
-				}
-				buf.WriteString(field.TypeName)
-				buf.WriteString(" ")
-				// 递归构建子类型，标记为联合子类型以保持花括号
-				set, err := g.buildSelectionSet(field.TypeParser, field.Inline, true, level+1)
-				if err != nil {
-					return "", fmt.Errorf("failed to build type for field [%s]: %w", field.FieldName, err)
-				}
-				buf.WriteString(set)
-			} else {
-				return "", fmt.Errorf("field [%s] in union type [%s] should be a struct type", field.FieldName, typeParser.source.String())
+				buf.WriteString(directives)
+				continue
+			}
+			// 递归构建子类型，标记为联合子类型以保持花括号；分支内的字段属于类型条件指定的类型
+			g.parentType = field.TypeName
+			set, err := g.buildSelectionSet(field.TypeParser, field, field.Inline, true, level+1)
+			if err != nil {
+				return "", err
 			}
+			// 分支字段使用 "... on TypeName" 语法
+			buf.WriteString("... on " + field.TypeName + directives + " " + set)
 			continue
 		}
 		// 处理匿名嵌入字段：直接展开字段内容，不添加字段名
 		if field.Inline {
-			set, err := g.buildSelectionSet(field.TypeParser, true, false, level)
+			directives, err := g.buildDirectives(field)
 			if err != nil {
-				return "", fmt.Errorf("failed to build type for field [%s]: %w", field.FieldName, err)
+				return "", err
+			}
+			if directives == "" || field.TypeParser == nil {
+				set, err := g.buildSelectionSet(field.TypeParser, field, true, false, level)
+				if err != nil {
+					return "", err
+				}
+				buf.WriteString(set)
+				continue
+			}
+			// 带指令的匿名嵌入字段无法直接平铺：封装为 Fragment 时输出 "...Name @dir"，否则输出无类型条件的内联片段 "... @dir{ ... }"
+			spec := g.fragmentFor(field.TypeParser, field)
+			set, err := g.buildSelectionSet(field.TypeParser, field, true, true, level+1)
+			if err != nil {
+				return "", err
+			}
+			buf.WriteString("\n")
+			buf.WriteString(g.indentWithLevel(level + 1))
+			if spec != nil {
+				buf.WriteString("..." + spec.name + directives)
+			} else {
+				buf.WriteString("..." + directives + set)
 			}
-			buf.WriteString(set)
 		} else {
 			// 处理普通字段：添加字段名和适当缩进
 			buf.WriteString("\n")
-			buf.WriteString(indentWithLevel(level + 1))
+			buf.WriteString(g.indentWithLevel(level + 1))
 			buf.WriteString(field.FieldName)
 			// 构建字段参数
-			args, err := g.buildFieldArgs(field)
+			fieldParent := g.fieldParentType(parentType, field.GraphQLName())
+			args, err := g.buildFieldArgs(field, fieldParent)
 			if err != nil {
 				return "", err
 			}
 			buf.WriteString(args)
+			directives, err := g.buildDirectives(field)
+			if err != nil {
+				return "", err
+			}
+			buf.WriteString(directives)
+			if g.options.Schema != nil {
+				g.parentType = g.options.Schema.FieldTypeName(fieldParent, field.GraphQLName())
+			}
 			// 递归构建嵌套类型，层级递增
-			set, err := g.buildSelectionSet(field.TypeParser, false, false, level+1)
+			set, err := g.buildSelectionSet(field.TypeParser, field, false, false, level+1)
 			if err != nil {
-				return "", fmt.Errorf("failed to build type for field [%s]: %w", field.FieldName, err)
+				return "", err
 			}
 			buf.WriteString(set)
 		}
 	}
 	// 闭合花括号，与开头的花括号对应
-	if !inlineType || isUnionSubType {
+	if !inlineType || isUnionSubType || spec != nil {
 		buf.WriteString("\n")
-		buf.WriteString(indentWithLevel(level))
+		buf.WriteString(g.indentWithLevel(level))
 		buf.WriteString("}")
 
-		// 处理重用类型：当类型被多次引用且不是顶级类型时，应封装为 Fragment（匿名结构体无法生成 Fragment，跳过）
-		if typeParser.Reused > 1 && typeParser.source.Name() != "" {
-			// 生成 Fragment 名称时，使用单独的 result 切片来避免修改原始 split 导致的索引混乱
-			split := strings.Split(typeParser.source.String(), ".")
-			var result []string
-			for _, s := range split {
-				if s == "" {
-					continue
-				}
-				// 首字母大写
-				runes := []rune(s)
-				if len(runes) > 0 {
-					runes[0] = unicode.ToUpper(runes[0])
-				}
-				result = append(result, string(runes))
-			}
-			fragmentName := strings.Join(result, "")
-			fragmentType := typeParser.source.Name()
-			fragment := fmt.Sprintf("fragment %s on %s%s", fragmentName, fragmentType, buf.String())
-			g.FragmentMap[typeParser.source] = &Fragment{
-				Name: strings.ReplaceAll(fragmentName, ".", "_"),
-				Type: fragmentType,
-				Body: fragment,
+		// 封装为 Fragment：记录定义并以展开语法引用
+		if spec != nil {
+			g.registerFragment(typeParser.source, &Fragment{
+				Name: spec.name,
+				Type: spec.on,
+				Body: fmt.Sprintf("fragment %s on %s%s", spec.name, spec.on, buf.String()),
+			})
+			return g.fragmentSpread(spec.name, inlineType && !isUnionSubType, spreadLevel), nil
+		}
+	}
+	return buf.String(), nil
+}
+
+// buildFieldArgs 构建字段参数字符串，返回形如 "(a: 1, b: $x)" 的片段，parent 为字段所属的 schema 类型名
+func (g *Builder) buildFieldArgs(field *FieldParser, parent string) (string, error) {
+	if field == nil || field.TagValue == nil {
+		return "", nil
+	}
+	var argType func(arg string) string
+	if g.options.Schema != nil && parent != "" {
+		argType = func(arg string) string {
+			return g.options.Schema.ArgumentType(parent, field.GraphQLName(), arg)
+		}
+	}
+	return g.buildArgs(field.TagValue.Args, field.TagValue.ArgNames, "", argType)
+}
+
+// fieldParentType 返回字段所属的 schema 类型名：顶层字段未指定 Operation 时依次在 query、mutation、subscription 根类型中查找
+func (g *Builder) fieldParentType(parent, field string) string {
+	if g.options.Schema == nil || parent != "" {
+		return parent
+	}
+	for _, operation := range []string{"query", "mutation", "subscription"} {
+		root := g.options.Schema.RootTypeName(operation)
+		if root != "" && g.options.Schema.FieldTypeName(root, field) != "" {
+			return root
+		}
+	}
+	return ""
+}
+
+// buildDirectives 构建字段上的指令字符串，返回形如 " @include(if:$withEmail) @cached(ttl:60)" 的片段
+// 指令参数中的匿名占位符以 "<指令名>_<参数名>" 参与变量命名，避免与字段参数重名
+func (g *Builder) buildDirectives(field *FieldParser) (string, error) {
+	if field == nil || field.TagValue == nil || len(field.TagValue.Directives) == 0 {
+		return "", nil
+	}
+	buf := new(strings.Builder)
+	for _, directive := range field.TagValue.Directives {
+		var argType func(arg string) string
+		if g.options.Schema != nil {
+			argType = func(arg string) string {
+				return g.options.Schema.DirectiveArgumentType(directive.Name, arg)
 			}
-			return fmt.Sprintf("{ ...%s }", fragmentName), nil
 		}
+		args, err := g.buildArgs(directive.Args, directive.ArgNames, directive.Name+"_", argType)
+		if err != nil {
+			return "", err
+		}
+		buf.WriteString(" @")
+		buf.WriteString(directive.Name)
+		buf.WriteString(args)
 	}
 	return buf.String(), nil
 }
 
-// buildFieldArgs 构建字段参数字符串，返回形如 "(a: 1, b: $x)" 的片段
-func (g *Builder) buildFieldArgs(field *FieldParser) (string, error) {
-	if field == nil || field.TagValue == nil || len(field.TagValue.Args) == 0 {
+// buildArgs 按 names 顺序构建参数列表，keyPrefix 仅用于匿名占位符的变量命名，
+// argType 不为空时用于查询参数在 schema 中的类型，以补全未声明类型的变量
+func (g *Builder) buildArgs(args map[string]*Arg, names []string, keyPrefix string, argType func(arg string) string) (string, error) {
+	if len(args) == 0 {
 		return "", nil
 	}
 
-	parts := make([]string, 0, len(field.TagValue.Args))
-	for key, arg := range field.TagValue.Args {
-		value, err := g.buildArgumentValue(key, arg)
+	parts := make([]string, 0, len(args))
+	for _, key := range names {
+		location := ""
+		if argType != nil && args[key] != nil {
+			location = argType(key)
+		}
+		value, err := g.buildArgumentValue(keyPrefix+key, args[key], location)
 		if err != nil {
 			return "", err
 		}
@@ -209,50 +376,142 @@ func CamelToSnake(s string) string {
 	return string(result)
 }
 
-// buildArgumentValue 根据占位符与自定义名生成参数值字符串
-func (g *Builder) buildArgumentValue(key string, arg *Arg) (string, error) {
+// buildArgumentValue 根据占位符与自定义名生成参数值字符串，location 为参数在 schema 中的类型（未设置 Schema 时为空），
+// 用于补全未声明类型的变量（含复合字面量中的变量占位符）
+func (g *Builder) buildArgumentValue(key string, arg *Arg, location string) (string, error) {
 	if arg == nil {
 		return "", nil
 	}
 
 	if arg.ArgValue.Type == "variable" {
-		varName := arg.VarName
-		if varName == "" {
-			varName = CamelToSnake(strings.ReplaceAll(strings.Join(g.currentPaths, "_")+"_"+key, ":", "_"))
-		}
-		if variable, ok := g.VariableMap[varName]; ok {
-			if variable.Type != arg.GraphQLType {
-				return "", fmt.Errorf("变量 %s 类型不统一：[%s]<==>[%s]", varName, variable.Type, arg.GraphQLType)
+		return g.buildVariable(key, &VariableRef{
+			Name:       arg.VarName,
+			Type:       arg.GraphQLType,
+			HasDefault: arg.HasDefault,
+			DefaultVal: arg.DefaultVal,
+		}, location)
+	}
+	switch arg.Value.(type) {
+	case ObjectValue, ListValue:
+		return g.buildValue(key, arg.Value, location)
+	}
+	if arg.Value == nil {
+		// 字面量参数为 nil 时省略该参数
+		return "", nil
+	}
+	return g.formatValue(arg.Value)
+}
+
+// buildValue 递归生成复合字面量，嵌套的变量占位符以 "<参数名>_<字段名或下标>" 参与匿名变量命名
+func (g *Builder) buildValue(key string, value any, location string) (string, error) {
+	switch val := value.(type) {
+	case *VariableRef:
+		return g.buildVariable(key, val, location)
+	case ObjectValue:
+		parts := make([]string, len(val))
+		for i, field := range val {
+			fieldLocation := ""
+			if g.options.Schema != nil && location != "" {
+				fieldLocation = g.options.Schema.InputFieldType(namedType(location), field.Name)
 			}
-			g.VariableMap[varName].Paths = append(g.VariableMap[varName].Paths, strings.Join(g.currentPaths, "/"))
-		} else {
-			g.VariableMap[varName] = &Variable{
-				Name:         "$" + varName,
-				Paths:        []string{strings.Join(g.currentPaths, "/")},
-				Type:         arg.GraphQLType,
-				HasDefault:   arg.HasDefault,
-				DefaultValue: arg.DefaultVal,
+			item, err := g.buildValue(key+"_"+field.Name, field.Value, fieldLocation)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = field.Name + ":" + item
+		}
+		return "{" + strings.Join(parts, ",") + "}", nil
+	case ListValue:
+		parts := make([]string, len(val))
+		for i, item := range val {
+			s, err := g.buildValue(fmt.Sprintf("%s_%d", key, i), item, listItemType(location))
+			if err != nil {
+				return "", err
 			}
+			parts[i] = s
 		}
-		return g.VariableMap[varName].Name, nil
+		return "[" + strings.Join(parts, ",") + "]", nil
+	default:
+		return g.formatValue(value)
 	}
-	return formatLiteralArgValue(arg.Value), nil
 }
 
-// formatLiteralArgValue 将字面量参数格式化为 GraphQL 查询中的写法（字符串加引号等）
-func formatLiteralArgValue(v interface{}) string {
-	if v == nil {
-		return ""
+// buildVariable 注册变量并返回其名称（含 "$"）；location 仅在变量未声明类型时用于推断类型
+func (g *Builder) buildVariable(key string, ref *VariableRef, location string) (string, error) {
+	inferredType := ""
+	if ref.Type == "" {
+		inferredType = location
+	}
+	varName := ref.Name
+	if varName == "" {
+		varName = g.options.VariableNamer(g.currentPaths, key)
 	}
-	switch val := v.(type) {
-	case string:
-		return `"` + strings.ReplaceAll(val, `"`, `\"`) + `"`
-	case bool:
-		if val {
-			return "true"
+	goPath, graphQLPath := joinPath(g.root, g.segments)
+	if variable, ok := g.VariableMap[varName]; ok {
+		switch {
+		case inferredType != "":
+			// 推断的类型只用于补全，不与已有类型比较
+			if variable.Type == "" {
+				variable.Type, variable.inferred = inferredType, true
+			}
+		case variable.inferred && ref.Type != "":
+			variable.Type, variable.inferred = ref.Type, false
+		case !variable.inferred && variable.Type != ref.Type:
+			// 收集模式下保留先声明的类型
+			err := g.fieldError(ErrVariableTypeConflict, variable.Name, "variable %s is declared with conflicting types %s and %s", variable.Name, variable.Type, ref.Type)
+			if err := g.report(err); err != nil {
+				return "", err
+			}
 		}
-		return "false"
-	default:
-		return fmt.Sprint(v)
+		variable.Paths = append(variable.Paths, strings.Join(g.currentPaths, "/"))
+		variable.GoPaths = append(variable.GoPaths, goPath)
+	} else {
+		varType := ref.Type
+		if varType == "" {
+			varType = inferredType
+		}
+		g.VariableMap[varName] = &Variable{
+			Name:         "$" + varName,
+			Paths:        []string{strings.Join(g.currentPaths, "/")},
+			Type:         varType,
+			HasDefault:   ref.HasDefault,
+			DefaultValue: ref.DefaultVal,
+			GoPaths:      []string{goPath},
+			inferred:     inferredType != "",
+			graphQLPath:  graphQLPath,
+		}
+		g.variableOrder = append(g.variableOrder, varName)
+	}
+	return g.VariableMap[varName].Name, nil
+}
+
+// fieldError 构造当前字段的 FieldError
+func (g *Builder) fieldError(kind error, subject, format string, args ...any) *FieldError {
+	err := NewError(kind, subject, format, args...)
+	err.GoPath, err.GraphQLPath = joinPath(g.root, g.segments)
+	return err
+}
+
+// namedType 去掉类型的列表与非空修饰，如 "[Status!]!" => "Status"
+func namedType(typ string) string {
+	return strings.Trim(typ, "[]!")
+}
+
+// listItemType 返回列表类型的元素类型，如 "[Status!]!" => "Status!"；非列表类型返回空字符串
+func listItemType(typ string) string {
+	typ = strings.TrimSuffix(typ, "!")
+	if strings.HasPrefix(typ, "[") && strings.HasSuffix(typ, "]") {
+		return typ[1 : len(typ)-1]
+	}
+	return ""
+}
+
+// formatValue 将字面量值格式化为 GraphQL 查询中的写法（见 FormatValue），Options.BlockStrings 时多行字符串输出为块字符串；
+// 无法格式化的值返回带当前字段路径的 ErrInvalidValue
+func (g *Builder) formatValue(v any) (string, error) {
+	s, err := formatValue(v, g.options.BlockStrings)
+	if fieldErr, ok := err.(*FieldError); ok {
+		fieldErr.GoPath, fieldErr.GraphQLPath = joinPath(g.root, g.segments)
 	}
+	return s, err
 }
@@ -0,0 +1,177 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var jsonUnmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+
+// Decode 按 typeParser 描述的选择集将 GraphQL 响应中的 data 解码到 v（必须为非 nil 指针）。
+// 字段按生成文档时使用的响应键匹配（设置 alias 时为别名），因此无需在 json tag 中重复别名；
+// 无选择集的字段（标量、实现 json.Unmarshaler 的类型等）交给 encoding/json 解码。
+func Decode(typeParser *TypeParser, data []byte, v any) error {
+	if v == nil {
+		return NewError(ErrNilInput, "", "decode target must be a non-nil pointer")
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr {
+		return NewError(ErrTypeMismatch, fmt.Sprint(rv.Type()), "decode target must be a non-nil pointer, got %v", rv.Type())
+	}
+	if rv.IsNil() {
+		return NewError(ErrNilInput, fmt.Sprint(rv.Type()), "decode target must be a non-nil pointer")
+	}
+	return decodeValue(typeParser, json.RawMessage(data), rv.Elem(), nil)
+}
+
+// decodeValue 递归解码单个值，path 为响应键路径，用于错误提示
+func decodeValue(typeParser *TypeParser, raw json.RawMessage, rv reflect.Value, path []string) error {
+	if isJSONNull(raw) {
+		switch rv.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+			rv.SetZero()
+		}
+		return nil
+	}
+	if typeParser == nil || implementsUnmarshaler(rv) {
+		if err := json.Unmarshal(raw, rv.Addr().Interface()); err != nil {
+			return decodeError(path, err)
+		}
+		return nil
+	}
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return decodeValue(typeParser, raw, rv.Elem(), path)
+	case reflect.Slice:
+		var items []json.RawMessage
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return decodeError(path, err)
+		}
+		slice := reflect.MakeSlice(rv.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := decodeValue(typeParser, item, slice.Index(i), append(path, fmt.Sprint(i))); err != nil {
+				return err
+			}
+		}
+		rv.Set(slice)
+		return nil
+	case reflect.Struct:
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &object); err != nil {
+			return decodeError(path, err)
+		}
+		return decodeFields(typeParser, object, rv, path)
+	default:
+		if err := json.Unmarshal(raw, rv.Addr().Interface()); err != nil {
+			return decodeError(path, err)
+		}
+		return nil
+	}
+}
+
+// decodeFields 将响应对象中的键解码到结构体字段，匿名嵌入字段与父级共用同一个响应对象
+func decodeFields(typeParser *TypeParser, object map[string]json.RawMessage, rv reflect.Value, path []string) error {
+	if typeParser.Union || typeParser.Interface {
+		return decodeUnion(typeParser, object, rv, path)
+	}
+	for _, field := range typeParser.Fields {
+		fv := rv.FieldByIndex(field.source.Index)
+		if field.Inline {
+			target, ok := allocStruct(fv)
+			if !ok || field.TypeParser == nil {
+				continue
+			}
+			if err := decodeFields(field.TypeParser, object, target, path); err != nil {
+				return err
+			}
+			continue
+		}
+		key := field.ResponseKey()
+		raw, ok := object[key]
+		if !ok {
+			continue
+		}
+		if err := decodeValue(field.TypeParser, raw, fv, append(path, key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeUnion 根据响应中的 __typename 只填充匹配的联合类型（或接口类型）分支，其余分支重置为零值（指针分支为 nil），
+// 避免 encoding/json 将共有字段（如 id）写入所有分支或因嵌入冲突而丢弃；接口类型的公共字段始终解码
+func decodeUnion(typeParser *TypeParser, object map[string]json.RawMessage, rv reflect.Value, path []string) error {
+	var typename string
+	if raw, ok := object["__typename"]; ok && !isJSONNull(raw) {
+		if err := json.Unmarshal(raw, &typename); err != nil {
+			return decodeError(append(path, "__typename"), err)
+		}
+	}
+	for _, field := range typeParser.Fields {
+		fv := rv.FieldByIndex(field.source.Index)
+		if !field.Inline {
+			key := field.ResponseKey()
+			if raw, ok := object[key]; ok {
+				if err := decodeValue(field.TypeParser, raw, fv, append(path, key)); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		fv.SetZero()
+		if field.TypeParser == nil || !field.matchesTypename(typename) {
+			continue
+		}
+		target, ok := allocStruct(fv)
+		if !ok {
+			continue
+		}
+		if err := decodeFields(field.TypeParser, object, target, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matchesTypename 判断联合类型（或接口类型）分支是否对应响应中的 __typename：匹配 type= 指定的类型名或 Go 类型名
+func (f *FieldParser) matchesTypename(typename string) bool {
+	if typename == "" {
+		return false
+	}
+	return f.TypeName == typename || (f.TypeParser != nil && f.TypeParser.source.Name() == typename)
+}
+
+// allocStruct 解开（必要时分配）指针，返回可写入的结构体值
+func allocStruct(fv reflect.Value) (reflect.Value, bool) {
+	for fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			fv.Set(reflect.New(fv.Type().Elem()))
+		}
+		fv = fv.Elem()
+	}
+	return fv, fv.Kind() == reflect.Struct
+}
+
+func implementsUnmarshaler(rv reflect.Value) bool {
+	return rv.CanAddr() && rv.Addr().Type().Implements(jsonUnmarshalerType)
+}
+
+func isJSONNull(raw json.RawMessage) bool {
+	return len(raw) == 0 || bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+}
+
+// decodeError 构造解码失败的 FieldError，GraphQLPath 为出错值的响应键路径，底层的 encoding/json 错误可通过 errors.As 取出
+func decodeError(path []string, err error) error {
+	graphQLPath := strings.Join(path, ".")
+	fieldErr := &FieldError{GraphQLPath: graphQLPath, Kind: ErrDecode, Detail: "failed to decode response", Err: err}
+	if graphQLPath != "" {
+		fieldErr.Detail += " at [" + graphQLPath + "]"
+	}
+	return fieldErr
+}
@@ -0,0 +1,90 @@
+package core
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/lascyb/tagkit"
+)
+
+// Directive GraphQL 指令，如 @include(if:$withEmail)、@cached(ttl:60)
+// 可声明在普通字段、联合类型分支（内联片段）与匿名嵌入字段（片段展开/内联片段）上
+type Directive struct {
+	Name     string          // 指令名（不含 "@"）
+	Args     map[string]*Arg // 指令参数，语法与字段参数一致
+	ArgNames []string        // 参数名按书写顺序排列
+}
+
+// splitDirectives 从原始 tag 中拆出以 "@" 开头的指令段，返回剩余部分与解析后的指令
+// 例如 "email,@include(if:$withEmail:Boolean!)" => "email", [@include(if:$withEmail:Boolean!)]
+func splitDirectives(tag string) (string, []*Directive, error) {
+	if !strings.Contains(tag, "@") {
+		return tag, nil, nil
+	}
+	var rest []string
+	var directives []*Directive
+	for i, part := range splitTopLevel(tag, ',') {
+		trimmed := strings.TrimSpace(part)
+		if !strings.HasPrefix(trimmed, "@") {
+			rest = append(rest, part)
+			continue
+		}
+		if i == 0 {
+			// 首段为字段名位置，保留空占位以免后续标记被误当作字段名
+			rest = append(rest, "")
+		}
+		directive, err := parseDirective(trimmed[1:])
+		if err != nil {
+			return "", nil, err
+		}
+		directives = append(directives, directive)
+	}
+	return strings.Join(rest, ","), directives, nil
+}
+
+// parseDirective 解析单个指令（不含 "@"），参数部分复用 tagkit 的字段参数语法
+func parseDirective(raw string) (*Directive, error) {
+	name, _, _ := strings.Cut(raw, "(")
+	name = strings.TrimSpace(name)
+	if !IsGraphQLName(name) {
+		return nil, fmt.Errorf("invalid directive name [@%s]", name)
+	}
+	directive := &Directive{Name: name, Args: map[string]*Arg{}}
+	if !strings.Contains(raw, "(") {
+		return directive, nil
+	}
+	raw, parsedArgs, err := splitValueArgs(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid directive [@%s]: %w", name, err)
+	}
+	tagValue, err := tagkit.ParseTagValue(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid directive [@%s]: %w", name, err)
+	}
+	if tagValue == nil {
+		return directive, nil
+	}
+	if directive.Args, err = newArgs(tagValue.Args); err != nil {
+		return nil, fmt.Errorf("invalid directive [@%s]: %w", name, err)
+	}
+	maps.Copy(directive.Args, parsedArgs)
+	directive.ArgNames = orderArgNames(scanArgNames(raw), tagValue.Args)
+	return directive, nil
+}
+
+// IsGraphQLName 判断字符串是否为合法的 GraphQL Name（/[_A-Za-z][_0-9A-Za-z]*/），可用于校验操作名、Fragment 名等
+func IsGraphQLName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case i > 0 && r >= '0' && r <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
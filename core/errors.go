@@ -0,0 +1,143 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+)
+
+// 错误类别，生成、变量解析、赋值与解码响应过程中返回的 *FieldError 均可通过 errors.Is 判断类别，
+// 如 errors.Is(err, core.ErrVariableTypeConflict)
+var (
+	ErrNilInput             = errors.New("nil input")
+	ErrNotStruct            = errors.New("not a struct type")
+	ErrCircularReference    = errors.New("circular reference")
+	ErrInvalidUnion         = errors.New("invalid union or interface type")
+	ErrInvalidTag           = errors.New("invalid graphql tag")
+	ErrVariableTypeConflict = errors.New("variable type conflict")
+	ErrMissingVariableType  = errors.New("missing variable type")
+	ErrInvalidVariables     = errors.New("invalid variables struct")
+	ErrUnusedVariable       = errors.New("unused variable")
+	ErrUnknownVariable      = errors.New("unknown variable")
+	ErrAmbiguousPath        = errors.New("ambiguous variable path")
+	ErrDuplicateValue       = errors.New("duplicate variable value")
+	ErrNullValue            = errors.New("null value for non-null variable")
+	ErrInvalidValue         = errors.New("invalid value")
+	ErrMissingValue         = errors.New("missing value for non-null variable")
+	ErrTypeMismatch         = errors.New("decode target type mismatch")
+	ErrDecode               = errors.New("failed to decode response")
+)
+
+// FieldError 携带字段定位信息的错误，可通过 errors.As 取出，通过 errors.Is 判断 Kind 与底层错误
+type FieldError struct {
+	GoPath      string // Go 结构体字段路径，如 "ProductQuery.Items.Nodes"；与具体字段无关时为空
+	GraphQLPath string // GraphQL 响应路径，如 "items.nodes"（设置别名时为别名，匿名嵌入字段不出现在路径中）
+	Kind        error  // 错误类别，为本包的 Err* 之一
+	Subject     string // 出错的对象，如变量名 "$id"、tag 原文或类型名
+	Detail      string // 英文描述，不含路径
+	Err         error  // 底层错误（如 tag 的语法错误），可为 nil
+}
+
+// ErrorFormatter 自定义 FieldError 的错误信息，可用于本地化；返回空字符串时使用默认的英文信息
+type ErrorFormatter func(e *FieldError) string
+
+var errorFormatter atomic.Pointer[ErrorFormatter]
+
+// SetErrorFormatter 设置全局的错误信息格式化函数，传入 nil 恢复默认的英文信息
+func SetErrorFormatter(f ErrorFormatter) {
+	if f == nil {
+		errorFormatter.Store(nil)
+		return
+	}
+	errorFormatter.Store(&f)
+}
+
+func (e *FieldError) Error() string {
+	if f := errorFormatter.Load(); f != nil {
+		if msg := (*f)(e); msg != "" {
+			return msg
+		}
+	}
+	return e.Message()
+}
+
+// Message 返回默认的英文错误信息，格式为 "GoPath: Detail: Err"
+func (e *FieldError) Message() string {
+	msg := e.Detail
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	if e.GoPath != "" {
+		msg = e.GoPath + ": " + msg
+	}
+	return msg
+}
+
+// Unwrap 返回错误类别与底层错误，供 errors.Is / errors.As 使用
+func (e *FieldError) Unwrap() []error {
+	if e.Err != nil {
+		return []error{e.Kind, e.Err}
+	}
+	return []error{e.Kind}
+}
+
+// NewError 构造不含字段路径的 FieldError
+func NewError(kind error, subject, format string, args ...any) *FieldError {
+	return &FieldError{Kind: kind, Subject: subject, Detail: fmt.Sprintf(format, args...)}
+}
+
+// VariableError 构造与变量相关的 FieldError，路径取变量首次出现的位置
+func VariableError(v *Variable, kind error, format string, args ...any) *FieldError {
+	err := NewError(kind, v.Name, format, args...)
+	if len(v.GoPaths) > 0 {
+		err.GoPath = v.GoPaths[0]
+	}
+	err.GraphQLPath = v.graphQLPath
+	return err
+}
+
+// FieldErrors 展开 errors.Join 合并的错误，按顺序返回其中的全部 *FieldError
+func FieldErrors(err error) []*FieldError {
+	switch e := err.(type) {
+	case *FieldError:
+		return []*FieldError{e}
+	case interface{ Unwrap() []error }:
+		var result []*FieldError
+		for _, item := range e.Unwrap() {
+			result = append(result, FieldErrors(item)...)
+		}
+		return result
+	case interface{ Unwrap() error }:
+		return FieldErrors(e.Unwrap())
+	}
+	return nil
+}
+
+// pathSegment 字段路径中的一段：Go 字段名与 GraphQL 响应键（匿名嵌入字段没有响应键）
+type pathSegment struct {
+	goName string
+	key    string
+}
+
+// joinPath 由根类型名与字段路径拼出 Go 字段路径与 GraphQL 响应路径
+func joinPath(root string, segments []pathSegment) (goPath, graphQLPath string) {
+	goNames := []string{root}
+	var keys []string
+	for _, segment := range segments {
+		goNames = append(goNames, segment.goName)
+		if segment.key != "" {
+			keys = append(keys, segment.key)
+		}
+	}
+	return strings.Join(goNames, "."), strings.Join(keys, ".")
+}
+
+// typeName 返回用于错误路径的类型名，匿名类型使用其字面表示
+func typeName(t reflect.Type) string {
+	if name := t.Name(); name != "" {
+		return name
+	}
+	return t.String()
+}
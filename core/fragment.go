@@ -0,0 +1,301 @@
+package core
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// fragmentSpec 选择集封装为 Fragment 时使用的名称与类型条件
+type fragmentSpec struct {
+	name     string
+	on       string
+	explicit bool // 由字段上的 fragment 标记指定
+}
+
+// fragmentUsage 规划 Fragment 时统计的某个类型的引用情况
+type fragmentUsage struct {
+	count      int           // 可以使用自动 Fragment 的引用次数，不含 nofragment 字段、type= 改写了类型条件的字段、泛型类型与自引用类型按深度展开的内层
+	standalone bool          // 上述引用中存在不平铺到父级的引用（普通字段或联合类型、接口类型的分支）
+	spec       *fragmentSpec // 字段上的 fragment 标记指定的 Fragment
+}
+
+// planFragments 在生成前遍历整个类型图，决定哪些类型封装为 Fragment 并分配名称，结果记录在 fragmentPlans 中。
+// 字段上的 fragment 标记优先（名称先于自动命名分配，类型条件取 type=），其次按 Options.Fragments：
+// FragmentAuto 时被多次引用的命名结构体封装为 Fragment，FragmentAlways 时所有不只以平铺方式引用的命名结构体都封装，FragmentNever 时都不封装；
+// 自动生成的 Fragment 以 Go 类型名为类型条件，type= 指定了其他类型的引用直接展开。
+// 匿名结构体与泛型类型（Go 类型名不是合法的 GraphQL 名称）只有带 fragment 标记的引用使用 Fragment。自动名称重名时按包路径与类型全名的顺序追加后缀
+func (g *Builder) planFragments(root *TypeParser) error {
+	usages := make(map[reflect.Type]*fragmentUsage)
+	var order []reflect.Type
+	// 第一遍：按每一处引用展开整个类型图，统计引用次数并记录 fragment 标记
+	err := g.walkReferences(root, func(parent *TypeParser, field *FieldParser) (bool, error) {
+		typ := field.TypeParser.source
+		usage, ok := usages[typ]
+		if !ok {
+			usage = new(fragmentUsage)
+			usages[typ] = usage
+			order = append(order, typ)
+		}
+		if err := g.planExplicitFragment(usage, typ, field); err != nil {
+			return false, err
+		}
+		if IsGraphQLName(typ.Name()) && field.TypeName == typ.Name() {
+			usage.count++
+			if !field.Inline || parent.Union || parent.Interface {
+				usage.standalone = true
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, typ := range order {
+		usage := usages[typ]
+		switch {
+		case usage.spec != nil:
+			g.fragmentPlans[typ] = usage.spec
+		case usage.count == 0:
+		case g.options.Fragments == FragmentAlways:
+			if usage.standalone {
+				g.fragmentPlans[typ] = &fragmentSpec{on: typ.Name()}
+			}
+		case g.options.Fragments != FragmentNever && usage.count > 1:
+			g.fragmentPlans[typ] = &fragmentSpec{on: typ.Name()}
+		}
+	}
+
+	// 第二遍：第一遍把 Fragment 内部的引用按父级的每一处引用重复统计，
+	// 按 Fragment 定义只展开一次重新统计，实际只被引用一次的自动 Fragment 改为直接展开
+	if g.options.Fragments == FragmentAuto {
+		counts := make(map[reflect.Type]int)
+		err := g.walkReferences(root, func(_ *TypeParser, field *FieldParser) (bool, error) {
+			typ := field.TypeParser.source
+			if g.fragmentFor(field.TypeParser, field) == nil {
+				return true, nil
+			}
+			counts[typ]++
+			return counts[typ] == 1, nil
+		})
+		if err != nil {
+			return err
+		}
+		for typ, spec := range g.fragmentPlans {
+			if !spec.explicit && counts[typ] <= 1 {
+				delete(g.fragmentPlans, typ)
+			}
+		}
+	}
+
+	// 名称与引用顺序无关：按包路径与类型全名的顺序分配，不同包中的同名类型（如 a/model.Product 与 b/model.Product）
+	// 总是由包路径靠前的类型使用不带后缀的名称，调整字段顺序不会改变 Fragment 名称
+	slices.SortFunc(order, func(a, b reflect.Type) int {
+		if c := strings.Compare(a.PkgPath(), b.PkgPath()); c != 0 {
+			return c
+		}
+		return strings.Compare(a.String(), b.String())
+	})
+	for _, typ := range order {
+		spec, ok := g.fragmentPlans[typ]
+		if !ok || spec.name != "" {
+			continue
+		}
+		if typ.Name() != "" {
+			spec.name = g.fragmentName(typ)
+		} else {
+			spec.name = g.uniqueFragmentName(spec.on)
+		}
+		g.fragmentNames[spec.name] = typ
+	}
+	return nil
+}
+
+// walkReferences 按生成时的展开方式深度优先遍历类型图中结构体字段的每一处引用，visit 返回 false 时不再遍历该引用的字段。
+// 自引用类型按深度展开的内层在外层中直接展开，不调用 visit；遍历时维护错误路径
+func (g *Builder) walkReferences(root *TypeParser, visit func(parent *TypeParser, field *FieldParser) (bool, error)) error {
+	var stack []reflect.Type
+	var walk func(typeParser *TypeParser) error
+	walk = func(typeParser *TypeParser) error {
+		stack = append(stack, typeParser.source)
+		segmentsCount := len(g.segments)
+		defer func() {
+			stack = stack[:len(stack)-1]
+			g.segments = g.segments[:segmentsCount]
+		}()
+		for _, field := range typeParser.Fields {
+			if field.TypeParser == nil {
+				continue
+			}
+			g.segments = append(g.segments[:segmentsCount], field.pathSegment())
+			descend := true
+			if field.flag("nofragment") == nil && !slices.Contains(stack, field.TypeParser.source) {
+				var err error
+				if descend, err = visit(typeParser, field); err != nil {
+					return err
+				}
+			}
+			if descend {
+				if err := walk(field.TypeParser); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	return walk(root)
+}
+
+// planExplicitFragment 记录字段上的 fragment 标记指定的 Fragment，同一类型的多个标记只能指定同一个名称；
+// 类型条件取 type= 或 Go 类型名，匿名结构体与泛型类型必须用 type= 指定
+func (g *Builder) planExplicitFragment(usage *fragmentUsage, typ reflect.Type, field *FieldParser) error {
+	flag := field.flag("fragment")
+	if flag == nil {
+		return nil
+	}
+	on := field.TypeName
+	switch {
+	case on == "":
+		return g.report(g.fieldError(ErrInvalidTag, field.FieldName, "fragment on an anonymous struct requires type= to name the type condition"))
+	case !IsGraphQLName(on):
+		return g.report(g.fieldError(ErrInvalidTag, field.FieldName, "fragment type condition %q is not a valid GraphQL name; set type= to the GraphQL type", on))
+	}
+	name := ""
+	if !flag.IsBoolean && flag.Value != nil {
+		name = strings.TrimSpace(fmt.Sprint(flag.Value))
+	}
+	if name != "" {
+		if !IsGraphQLName(name) {
+			return g.report(g.fieldError(ErrInvalidTag, field.FieldName, "fragment name %q is not a valid GraphQL name", name))
+		}
+		if usage.spec != nil && usage.spec.name != "" && usage.spec.name != name {
+			return g.report(g.fieldError(ErrInvalidTag, field.FieldName, "fragment %s conflicts with fragment %s of the same type", name, usage.spec.name))
+		}
+		if other, ok := g.fragmentNames[name]; ok && other != typ {
+			return g.report(g.fieldError(ErrInvalidTag, field.FieldName, "fragment name %s is already used by type %s", name, other))
+		}
+		g.fragmentNames[name] = typ
+	}
+	if usage.spec == nil {
+		usage.spec = &fragmentSpec{on: on, explicit: true}
+	}
+	if name != "" {
+		usage.spec.name = name
+	}
+	return nil
+}
+
+// fragmentFor 返回字段的选择集使用的 Fragment，nil 表示直接展开。
+// nofragment 字段、正在展开的类型（自引用类型按深度展开的内层）、匿名结构体不带 fragment 标记的引用
+// 以及类型条件与 Fragment 不同的引用（如 type= 指定了其他类型）直接展开
+func (g *Builder) fragmentFor(typeParser *TypeParser, field *FieldParser) *fragmentSpec {
+	if typeParser == nil || field == nil || field.flag("nofragment") != nil || g.expanding[typeParser.source] > 0 {
+		return nil
+	}
+	spec := g.fragmentPlans[typeParser.source]
+	switch {
+	case spec == nil:
+		return nil
+	case typeParser.source.Name() == "" && field.flag("fragment") == nil:
+		return nil
+	case field.TypeName != spec.on:
+		return nil
+	}
+	return spec
+}
+
+// fragmentSpread 返回引用 Fragment 的展开语法：平铺的匿名嵌入字段输出为单独一行的 "...Name"，否则作为选择集输出 "{ ...Name }"
+func (g *Builder) fragmentSpread(name string, flattened bool, level uint) string {
+	if flattened {
+		return fmt.Sprintf("\n%s...%s", g.indentWithLevel(level+1), name)
+	}
+	return fmt.Sprintf("{ ...%s }", name)
+}
+
+// registerFragment 记录生成完成的 Fragment；定义在第一次引用时生成，定义中引用的 Fragment 先完成，因此顺序总是依赖在前
+func (g *Builder) registerFragment(typ reflect.Type, fragment *Fragment) {
+	g.FragmentMap[typ] = fragment
+	g.fragmentOrder = append(g.fragmentOrder, typ)
+}
+
+// fragmentName 返回命名类型的 Fragment 名称：优先使用 Options.FragmentNamer，否则为 DefaultFragmentName；
+// 结果中的非法字符被去除，与其他类型的 Fragment 重名时追加数字后缀（如 "ModelProduct2"，分配顺序见 planFragments）
+func (g *Builder) fragmentName(typ reflect.Type) string {
+	name := ""
+	if g.options.FragmentNamer != nil {
+		name = g.options.FragmentNamer(typ)
+	}
+	if name == "" {
+		name = DefaultFragmentName(typ)
+	}
+	return g.uniqueFragmentName(sanitizeName(name))
+}
+
+// uniqueFragmentName 名称已被使用时追加从 2 开始的数字后缀
+func (g *Builder) uniqueFragmentName(name string) string {
+	if _, ok := g.fragmentNames[name]; !ok {
+		return name
+	}
+	for i := 2; ; i++ {
+		candidate := name + strconv.Itoa(i)
+		if _, ok := g.fragmentNames[candidate]; !ok {
+			return candidate
+		}
+	}
+}
+
+// DefaultFragmentName 默认的 Fragment 命名规则：包名与类型名首字母大写后拼接，如 "model.Product" => "ModelProduct"；
+// 泛型类型的类型参数只保留类型名依次拼接，如 "model.Connection[github.com/x/shop.Product]" => "ModelConnectionProduct"
+func DefaultFragmentName(typ reflect.Type) string {
+	pkg, _, _ := strings.Cut(typ.String(), ".")
+	return sanitizeName(upperFirst(pkg) + graphQLTypeName(typ.Name()))
+}
+
+// graphQLTypeName 把 Go 类型名转为合法的 GraphQL 名称：类型参数只保留类型名并依次拼接，
+// 如 "Connection[github.com/x/shop.Product]" => "ConnectionProduct"，普通类型名原样返回
+func graphQLTypeName(name string) string {
+	if IsGraphQLName(name) || name == "" {
+		return name
+	}
+	parts := strings.FieldsFunc(name, func(r rune) bool {
+		return r == '[' || r == ']' || r == ',' || r == '*' || r == ' '
+	})
+	for i, part := range parts {
+		// 去掉包路径限定，如 "github.com/x/shop.Product" => "Product"
+		if j := strings.LastIndex(part, "."); j >= 0 {
+			part = part[j+1:]
+		}
+		if i > 0 {
+			part = upperFirst(part)
+		}
+		parts[i] = part
+	}
+	return sanitizeName(strings.Join(parts, ""))
+}
+
+// sanitizeName 去除 GraphQL 名称中不允许的字符（只允许 ASCII 字母、数字与 "_"），以数字开头时补 "_"
+func sanitizeName(name string) string {
+	var buf strings.Builder
+	for _, r := range name {
+		if r == '_' || r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			buf.WriteRune(r)
+		}
+	}
+	s := buf.String()
+	if s == "" || (s[0] >= '0' && s[0] <= '9') {
+		s = "_" + s
+	}
+	return s
+}
+
+// upperFirst 首字母大写
+func upperFirst(s string) string {
+	runes := []rune(s)
+	if len(runes) > 0 {
+		runes[0] = unicode.ToUpper(runes[0])
+	}
+	return string(runes)
+}
@@ -0,0 +1,90 @@
+package core
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Options 单次生成使用的渲染与命名选项，零值字段使用默认行为
+type Options struct {
+	// Indent 每一层级的缩进字符串，为空时使用两个空格；不受 SetIndent 影响
+	Indent string
+	// NoIndent 为 true 时不输出缩进（仍按层级换行），忽略 Indent
+	NoIndent bool
+	// VariableNamer 为匿名占位符 "$" 生成变量名（不含 "$"）
+	// paths 为当前字段路径（含别名，如 "alias:field"），arg 为参数名；为空时使用 DefaultVariableName
+	VariableNamer func(paths []string, arg string) string
+	// FragmentNamer 为命名结构体生成 Fragment 名称，返回空字符串时使用 DefaultFragmentName；
+	// 结果中的非法字符会被去除，与其他类型重名时追加数字后缀
+	FragmentNamer func(typ reflect.Type) string
+	// Schema 提供 schema 中的参数类型（*schema.Schema 实现了该接口）；设置后未声明类型的变量（如 "id:$id"、"$"）
+	// 按其所在参数在 schema 中声明的类型补全，tag 中显式声明的类型优先
+	Schema Schema
+	// BlockStrings 为 true 时包含换行的字符串字面量输出为块字符串（"""..."""），无法原样表示的字符串仍输出为普通字符串
+	BlockStrings bool
+	// Operation 推断变量类型时使用的操作类型（"query"、"mutation"、"subscription"）；
+	// 为空时按顶层字段依次在 query、mutation、subscription 根类型中查找
+	Operation string
+	// AllErrors 为 true 时解析与生成遇到错误后继续遍历其余字段，返回包含全部错误（含缺少类型的变量）的 errors.Join，
+	// 便于一次修正所有问题；默认在第一个错误处返回
+	AllErrors bool
+	// MaxDepth 自引用类型（如 Children []Category）在同一路径上最多展开的层数，到达后省略该字段；
+	// 字段上的 depth=N 标记优先，均未设置时自引用类型返回循环引用错误
+	MaxDepth int
+	// DisableCache 为 true 时每次调用都重新解析与生成，不读取也不写入按类型缓存的结果；
+	// 设置了 VariableNamer、FragmentNamer 或 Schema 不可比较时同样不使用缓存；缓存数量上限见 graphql.SetCacheSize
+	DisableCache bool
+	// Fragments 选择集封装为 Fragment 的策略，零值为 FragmentAuto；字段上的 fragment / nofragment 标记优先
+	Fragments FragmentPolicy
+}
+
+// FragmentPolicy 选择集封装为 Fragment 的全局策略
+type FragmentPolicy int
+
+const (
+	// FragmentAuto 被多次引用的命名结构体封装为 Fragment（默认）
+	FragmentAuto FragmentPolicy = iota
+	// FragmentAlways 所有字段的命名结构体选择集都封装为 Fragment
+	FragmentAlways
+	// FragmentNever 不自动生成 Fragment，只有带 fragment 标记的字段封装为 Fragment
+	FragmentNever
+)
+
+// Schema 推断变量类型所需的 schema 信息，类型均使用 GraphQL 写法，查不到时返回空字符串
+type Schema interface {
+	// RootTypeName 返回操作类型对应的根类型名
+	RootTypeName(operation string) string
+	// FieldTypeName 返回 parent 类型上字段 field 的命名类型（去掉列表与非空修饰）
+	FieldTypeName(parent, field string) string
+	// ArgumentType 返回 parent 类型上字段 field 的参数 arg 的类型，如 "ID!"
+	ArgumentType(parent, field, arg string) string
+	// DirectiveArgumentType 返回指令 directive 的参数 arg 的类型，如 "Boolean!"
+	DirectiveArgumentType(directive, arg string) string
+	// InputFieldType 返回输入对象类型 input 的字段 field 的类型，用于推断复合字面量中的变量类型
+	InputFieldType(input, field string) string
+}
+
+// DefaultVariableName 默认的变量命名规则：字段路径与参数名以 "_" 连接后转为 snake_case
+func DefaultVariableName(paths []string, arg string) string {
+	return CamelToSnake(strings.ReplaceAll(strings.Join(paths, "_")+"_"+arg, ":", "_"))
+}
+
+// IndentUnit 返回每一层级实际使用的缩进：NoIndent 时为空字符串，Indent 为空时为两个空格
+func (o Options) IndentUnit() string {
+	switch {
+	case o.NoIndent:
+		return ""
+	case o.Indent == "":
+		return defaultIndentUnit
+	}
+	return o.Indent
+}
+
+// withDefaults 返回补齐默认值后的副本
+func (o Options) withDefaults() Options {
+	o.Indent = o.IndentUnit()
+	if o.VariableNamer == nil {
+		o.VariableNamer = DefaultVariableName
+	}
+	return o
+}
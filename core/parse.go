@@ -6,13 +6,59 @@ import (
 
 type Parser struct {
 	types    map[reflect.Type]*TypeParser
-	visiting map[reflect.Type]bool // 循环引用检测
-	root     *TypeParser
+	visiting map[reflect.Type]int // 循环引用检测：类型在当前路径上出现的次数
+	unrolled map[reflect.Type]int // 按 depth=N 或 Options.MaxDepth 展开自引用类型的次数
+	// unrolling 当前是否处于自引用类型的展开中；展开得到的类型解析结果与深度有关，不参与缓存与 Fragment 复用
+	unrolling int
+	maxDepth  int
+	root      *TypeParser
+	rootName  string        // 根结构体类型名，用于错误的 Go 字段路径
+	segments  []pathSegment // 当前正在解析的字段路径，用于错误定位
+	collect   bool          // 收集全部错误而非在第一个错误处返回，见 Options.AllErrors
+	cyclic    bool          // 自引用类型指向正在解析的自身而非返回循环引用错误，见 NewDecodeParser
+	errs      []error
 }
 
 func NewParser() *Parser {
+	return NewParserWithOptions(Options{})
+}
+
+// NewParserWithOptions 使用指定选项创建 Parser，使用 Options.AllErrors 与 Options.MaxDepth
+func NewParserWithOptions(options Options) *Parser {
 	return &Parser{
 		types:    make(map[reflect.Type]*TypeParser),
-		visiting: make(map[reflect.Type]bool),
+		visiting: make(map[reflect.Type]int),
+		unrolled: make(map[reflect.Type]int),
+		collect:  options.AllErrors,
+		maxDepth: options.MaxDepth,
 	}
 }
+
+// NewDecodeParser 创建只用于 Decode 的 Parser：未设置深度的自引用类型不返回循环引用错误，
+// 而是指向自身的解析结果（形成环），解码时按响应数据的实际层数递归；设置了 depth=N 的字段仍按 N 层展开
+func NewDecodeParser() *Parser {
+	p := NewParser()
+	p.cyclic = true
+	return p
+}
+
+// Errors 返回收集模式下记录的错误，按发现的顺序排列
+func (p *Parser) Errors() []error {
+	return p.errs
+}
+
+// report 收集模式下记录错误并返回 nil 以便继续解析，否则原样返回错误
+func (p *Parser) report(err error) error {
+	if !p.collect {
+		return err
+	}
+	p.errs = append(p.errs, err)
+	return nil
+}
+
+// fieldError 构造当前字段的 FieldError
+func (p *Parser) fieldError(kind error, subject, format string, args ...any) *FieldError {
+	err := NewError(kind, subject, format, args...)
+	err.GoPath, err.GraphQLPath = joinPath(p.rootName, p.segments)
+	return err
+}
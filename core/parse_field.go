@@ -1,8 +1,12 @@
 package core
 
 import (
+	"errors"
 	"fmt"
+	"maps"
 	"reflect"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/lascyb/tagkit"
@@ -26,13 +30,97 @@ type Arg struct {
 // TagValue 包装 tagkit.TagValue，Args 的 value 使用本包的 Arg 以携带 Type
 type TagValue struct {
 	*tagkit.TagValue
-	Args map[string]*Arg
+	Args       map[string]*Arg
+	ArgNames   []string     // 参数名按 tag 中书写的顺序排列，保证输出稳定
+	Directives []*Directive // 字段上声明的指令，按书写顺序排列
+}
+
+// clone 复制 tag 解析结果，参数、标记与指令均为新的副本
+func (v *TagValue) clone() *TagValue {
+	if v == nil {
+		return nil
+	}
+	clone := *v
+	if v.TagValue != nil {
+		tagValue := *v.TagValue
+		tagValue.Flags = slices.Clone(v.TagValue.Flags)
+		tagValue.Args = maps.Clone(v.TagValue.Args)
+		clone.TagValue = &tagValue
+	}
+	clone.Args = cloneArgs(v.Args)
+	clone.ArgNames = slices.Clone(v.ArgNames)
+	if v.Directives != nil {
+		clone.Directives = make([]*Directive, len(v.Directives))
+		for i, d := range v.Directives {
+			directive := *d
+			directive.Args = cloneArgs(d.Args)
+			directive.ArgNames = slices.Clone(d.ArgNames)
+			clone.Directives[i] = &directive
+		}
+	}
+	return &clone
+}
+
+func cloneArgs(args map[string]*Arg) map[string]*Arg {
+	if args == nil {
+		return nil
+	}
+	clone := make(map[string]*Arg, len(args))
+	for name, arg := range args {
+		item := *arg
+		clone[name] = &item
+	}
+	return clone
+}
+
+// StructField 返回字段对应的 Go 结构体字段
+func (f *FieldParser) StructField() reflect.StructField {
+	return f.source
+}
+
+// GraphQLName 返回字段在 schema 中的名称：设置别名（"alias:field"）时为冒号后的字段名
+func (f *FieldParser) GraphQLName() string {
+	if _, name, ok := strings.Cut(f.FieldName, ":"); ok {
+		return name
+	}
+	return f.FieldName
+}
+
+// ResponseKey 返回字段在响应 JSON 中的键：设置别名（"alias:field"）时为别名，否则为字段名
+func (f *FieldParser) ResponseKey() string {
+	if alias, _, ok := strings.Cut(f.FieldName, ":"); ok {
+		return alias
+	}
+	return f.FieldName
+}
+
+// flag 返回字段 tag 中名为 name 的标记，未设置时返回 nil
+func (f *FieldParser) flag(name string) *tagkit.FlagInfo {
+	if f.TagValue == nil || f.TagValue.TagValue == nil {
+		return nil
+	}
+	return flagByName(f.TagValue.Flags, name)
+}
+
+// pathSegment 返回字段在错误路径中的一段，匿名嵌入字段没有响应键
+func (f *FieldParser) pathSegment() pathSegment {
+	segment := pathSegment{goName: f.source.Name}
+	if !f.Inline {
+		segment.key = f.ResponseKey()
+	}
+	return segment
 }
 
 func (p *Parser) ParseField(field reflect.StructField) (*FieldParser, error) {
 	tagValue, err := parseFieldTagValue(field.Tag)
 	if err != nil {
-		return nil, err
+		tag, ok := field.Tag.Lookup("graphql")
+		if !ok {
+			tag = field.Tag.Get("json")
+		}
+		fieldErr := p.fieldError(ErrInvalidTag, tag, "invalid tag %q", tag)
+		fieldErr.Err = err
+		return nil, fieldErr
 	}
 	fieldName := field.Name
 	if tagValue != nil {
@@ -43,8 +131,8 @@ func (p *Parser) ParseField(field reflect.StructField) (*FieldParser, error) {
 			if len(tagValue.Flags) == 1 && tagValue.Flags[0].IsBoolean {
 				fieldName = tagValue.Flags[0].Name
 			}
-			// "__typename,union" 解析为两个布尔标记，需补全为 __typename 以便联合类型检测与输出
-			if hasFlag(tagValue.Flags, "union") {
+			// "__typename,union" / "__typename,interface" 解析为两个布尔标记，需补全为 __typename 以便联合类型检测与输出
+			if hasFlag(tagValue.Flags, "union") || hasFlag(tagValue.Flags, "interface") {
 				fieldName = "__typename"
 			}
 		}
@@ -54,6 +142,16 @@ func (p *Parser) ParseField(field reflect.StructField) (*FieldParser, error) {
 			}
 		}
 	}
+	fieldParser := &FieldParser{
+		source:    field,
+		FieldName: fieldName,
+		TagValue:  tagValue,
+		Inline:    field.Anonymous,
+	}
+	// 解析嵌套类型前补全当前字段的响应键，嵌套类型中的错误路径包含该字段
+	if len(p.segments) > 0 {
+		p.segments[len(p.segments)-1] = fieldParser.pathSegment()
+	}
 	fieldType := field.Type
 	// 支持多层指针 / 切片（例如 []*MetaInfo、[][]*MetaInfo），一路解开直到命中基础结构体类型
 	for fieldType.Kind() == reflect.Ptr || fieldType.Kind() == reflect.Slice {
@@ -62,7 +160,26 @@ func (p *Parser) ParseField(field reflect.StructField) (*FieldParser, error) {
 
 	var typeParser *TypeParser = nil
 	if fieldType.Kind() == reflect.Struct {
-		typeParser, err = p.ParseType(fieldType)
+		depth, err := recursionDepth(tagValue, p.maxDepth)
+		if err != nil {
+			fieldErr := p.fieldError(ErrInvalidTag, fieldName, "invalid depth for field %s", fieldName)
+			fieldErr.Err = err
+			return nil, fieldErr
+		}
+		switch {
+		case p.visiting[fieldType] == 0 || depth == 0:
+			// 非自引用字段，或未设置深度（自引用时由 ParseType 返回循环引用错误）
+			typeParser, err = p.ParseType(fieldType)
+		case p.unrolled[fieldType] >= depth:
+			// 到达最大深度，省略该字段
+			return nil, nil
+		default:
+			p.unrolled[fieldType]++
+			p.unrolling++
+			typeParser, err = p.ParseType(fieldType)
+			p.unrolled[fieldType]--
+			p.unrolling--
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -76,43 +193,14 @@ func (p *Parser) ParseField(field reflect.StructField) (*FieldParser, error) {
 		}
 	}
 
-	var fieldTagValue *TagValue = nil
-
-	if tagValue != nil {
-		fieldTagValue = &TagValue{
-			TagValue: tagValue,
-			Args:     make(map[string]*Arg),
-		}
-		for name, argVal := range tagValue.Args {
-			item := &Arg{ArgValue: argVal}
-			if argVal.Type == "variable" {
-				item.GraphQLType = argVal.VarType
-			}
-			if s, ok := argVal.Value.(string); ok && argVal.Type == "literal" {
-				raw := strings.TrimSpace(s)
-				if raw == "" {
-					return nil, fmt.Errorf("参数值不能定义为空")
-				}
-				// 字面量参数中的 ":" 仅视为普通字符，不做 value:type 拆分。
-				// 变量类型声明必须使用 $ 占位符语法（如 $:String! / $id:Int!）。
-				// 这确保了参数处理的一致性与可预测性。
-				item.Value = raw
-			}
-			fieldTagValue.Args[name] = item
-		}
-	}
-
-	return &FieldParser{
-		source:     field,
-		TypeParser: typeParser,
-		TypeName:   typeName,
-		FieldName:  fieldName,
-		TagValue:   fieldTagValue,
-		Inline:     field.Anonymous,
-	}, nil
+	fieldParser.TypeParser = typeParser
+	fieldParser.TypeName = typeName
+	return fieldParser, nil
 }
 
-func parseFieldTagValue(tag reflect.StructTag) (*tagkit.TagValue, error) {
+// parseFieldTagValue 解析字段 tag：指令段（以 "@" 开头）单独解析，其余部分交给 tagkit，
+// 并按 tag 中的书写顺序记录参数名（tagkit 的 Args 为 map，无法保留顺序）
+func parseFieldTagValue(tag reflect.StructTag) (*TagValue, error) {
 	value, ok := tag.Lookup("graphql")
 	if !ok {
 		value, ok = tag.Lookup("json")
@@ -121,12 +209,152 @@ func parseFieldTagValue(tag reflect.StructTag) (*tagkit.TagValue, error) {
 			if err != nil {
 				return nil, err
 			}
-			return &tagkit.TagValue{
-				Name: tagValue.Name,
+			return &TagValue{
+				TagValue: &tagkit.TagValue{Name: tagValue.Name},
+				Args:     map[string]*Arg{},
 			}, nil
 		}
 	}
-	return tagkit.ParseTagValue(value)
+	value, directives, err := splitDirectives(value)
+	if err != nil {
+		return nil, err
+	}
+	value, parsedArgs, err := splitValueArgs(value)
+	if err != nil {
+		return nil, err
+	}
+	tagValue, err := tagkit.ParseTagValue(value)
+	if err != nil {
+		return nil, err
+	}
+	if tagValue == nil {
+		if len(directives) == 0 {
+			return nil, nil
+		}
+		tagValue = &tagkit.TagValue{}
+	}
+	args, err := newArgs(tagValue.Args)
+	if err != nil {
+		return nil, err
+	}
+	maps.Copy(args, parsedArgs)
+	return &TagValue{
+		TagValue:   tagValue,
+		Args:       args,
+		ArgNames:   orderArgNames(scanArgNames(value), tagValue.Args),
+		Directives: directives,
+	}, nil
+}
+
+// newArgs 将 tagkit 解析出的参数包装为本包的 Arg
+func newArgs(values map[string]tagkit.ArgValue) (map[string]*Arg, error) {
+	args := make(map[string]*Arg, len(values))
+	for name, argVal := range values {
+		item := &Arg{ArgValue: argVal}
+		if argVal.Type == "variable" {
+			item.GraphQLType = argVal.VarType
+		}
+		if s, ok := argVal.Value.(string); ok && argVal.Type == "literal" {
+			raw := strings.TrimSpace(s)
+			if raw == "" {
+				return nil, errors.New("argument value cannot be empty")
+			}
+			// 字面量参数中的 ":" 仅视为普通字符，不做 value:type 拆分。
+			// 变量类型声明必须使用 $ 占位符语法（如 $:String! / $id:Int!）。
+			// 这确保了参数处理的一致性与可预测性。
+			item.Value = raw
+		}
+		args[name] = item
+	}
+	return args, nil
+}
+
+// scanArgNames 从原始 tag 的首段（如 "items(first:10,after:$)"）中按顺序提取参数名
+func scanArgNames(tag string) []string {
+	head := strings.TrimSpace(splitTopLevel(tag, ',')[0])
+	start := strings.IndexByte(head, '(')
+	if start < 0 || !strings.HasSuffix(head, ")") {
+		return nil
+	}
+	var names []string
+	for _, part := range splitTopLevel(head[start+1:len(head)-1], ',') {
+		name, _, ok := strings.Cut(part, ":")
+		if name = strings.TrimSpace(name); ok && name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// orderArgNames 以 tag 顺序为准，补齐未被扫描到的参数名（按字典序追加），确保覆盖 args 中的全部键
+func orderArgNames[V any](scanned []string, args map[string]V) []string {
+	names := make([]string, 0, len(args))
+	seen := make(map[string]bool, len(args))
+	for _, name := range scanned {
+		if _, ok := args[name]; ok && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	rest := make([]string, 0)
+	for name := range args {
+		if !seen[name] {
+			rest = append(rest, name)
+		}
+	}
+	slices.Sort(rest)
+	return append(names, rest...)
+}
+
+// splitTopLevel 按分隔符切分字符串，忽略引号与括号内部的分隔符
+func splitTopLevel(s string, sep byte) []string {
+	var parts []string
+	var quote byte
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if quote != 0 {
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '"', '\'':
+			quote = c
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case sep:
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// recursionDepth 读取字段的 depth=N 标记，未设置时返回 fallback
+func recursionDepth(tagValue *TagValue, fallback int) (int, error) {
+	if tagValue == nil {
+		return fallback, nil
+	}
+	f := flagByName(tagValue.Flags, "depth")
+	if f == nil {
+		return fallback, nil
+	}
+	if f.IsBoolean || f.Value == nil {
+		return 0, errors.New("depth requires a value, e.g. depth=3")
+	}
+	depth, err := strconv.Atoi(strings.TrimSpace(fmt.Sprint(f.Value)))
+	if err != nil || depth < 1 {
+		return 0, fmt.Errorf("depth must be a positive integer, got %v", f.Value)
+	}
+	return depth, nil
 }
 
 func hasFlag(flags []tagkit.FlagInfo, name string) bool {
@@ -1,46 +1,83 @@
 package core
 
 import (
-	"errors"
-	"fmt"
 	"reflect"
 )
 
 type TypeParser struct {
-	source reflect.Type
-	Fields []*FieldParser
-	Union  bool
-	Reused uint
+	source    reflect.Type
+	Fields    []*FieldParser
+	Union     bool
+	Interface bool // 接口类型：普通字段作为公共字段直接选择，匿名嵌入的命名结构体作为 "... on Type" 分支
+	Reused    uint
+}
+
+// Type 返回被解析的 Go 结构体类型
+func (t *TypeParser) Type() reflect.Type {
+	return t.source
+}
+
+// Clone 深拷贝解析结果（字段、tag 解析结果与子类型），原结果中共享的子类型在副本中同样共享
+func (t *TypeParser) Clone() *TypeParser {
+	return t.clone(make(map[*TypeParser]*TypeParser))
+}
+
+func (t *TypeParser) clone(seen map[*TypeParser]*TypeParser) *TypeParser {
+	if t == nil {
+		return nil
+	}
+	if clone, ok := seen[t]; ok {
+		return clone
+	}
+	clone := *t
+	seen[t] = &clone
+	clone.Fields = make([]*FieldParser, len(t.Fields))
+	for i, f := range t.Fields {
+		field := *f
+		field.TypeParser = f.TypeParser.clone(seen)
+		field.TagValue = f.TagValue.clone()
+		clone.Fields[i] = &field
+	}
+	return &clone
 }
 
 func (p *Parser) ParseType(typ reflect.Type) (*TypeParser, error) {
-	// 检查循环引用
-	if p.visiting[typ] {
-		typeName := typ.Name()
-		if typeName == "" {
-			typeName = typ.String()
+	if len(p.visiting) == 0 {
+		// 顶层调用：记录根类型名用于错误路径
+		p.rootName, p.segments = typeName(structType(typ)), nil
+	}
+	// 检查循环引用：按深度展开的自引用不计入
+	if p.visiting[typ] > p.unrolled[typ] {
+		if v := p.types[structType(typ)]; p.cyclic && v != nil {
+			return v, nil
 		}
-		return nil, fmt.Errorf("graphql: circular reference detected for type %s", typeName)
+		return nil, p.fieldError(ErrCircularReference, typeName(typ), "circular reference detected for type %s; set depth=N on the field or Options.MaxDepth to unroll it", typeName(typ))
 	}
 
 	// 标记为正在访问
-	p.visiting[typ] = true
+	p.visiting[typ]++
 	defer func() {
-		delete(p.visiting, typ)
+		if p.visiting[typ]--; p.visiting[typ] == 0 {
+			delete(p.visiting, typ)
+		}
 	}()
 
-	if typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice {
-		typ = typ.Elem()
-	}
+	typ = structType(typ)
 	if typ.Kind() != reflect.Struct {
-		return nil, errors.New("must be a struct type to convert")
+		return nil, p.fieldError(ErrNotStruct, typ.String(), "%s must be a struct type to convert", typ.String())
 	}
-	if v, ok := p.types[typ]; ok && v != nil {
+	if v, ok := p.types[typ]; ok && v != nil && p.unrolling == 0 {
 		v.Reused++
 		return v, nil
 	}
+	typeParser := &TypeParser{source: typ, Reused: 1}
+	if p.cyclic && p.unrolling == 0 {
+		// 先登记解析结果，字段中的自引用直接指向它
+		p.types[typ] = typeParser
+	}
 	fields := make([]*FieldParser, 0)
 	isUnionType := false
+	isInterfaceType := false
 	exportedCount := 0
 	for i := range typ.NumField() {
 		field := typ.Field(i)
@@ -48,38 +85,84 @@ func (p *Parser) ParseType(typ reflect.Type) (*TypeParser, error) {
 			continue
 		}
 		exportedCount++
+		p.segments = append(p.segments, pathSegment{goName: field.Name})
 		fieldParser, err := p.ParseField(field)
+		p.segments = p.segments[:len(p.segments)-1]
 		if err != nil {
-			return nil, err
+			// 收集模式下跳过出错的字段，继续解析其余字段
+			if err = p.report(err); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if fieldParser == nil {
+			// 自引用类型到达最大深度，省略该字段
+			continue
 		}
-		if fieldParser.FieldName == "__typename" && fieldParser.TagValue != nil && hasFlag(fieldParser.TagValue.Flags, "union") {
-			isUnionType = true
+		if fieldParser.FieldName == "__typename" && fieldParser.TagValue != nil {
+			isUnionType = isUnionType || hasFlag(fieldParser.TagValue.Flags, "union")
+			isInterfaceType = isInterfaceType || hasFlag(fieldParser.TagValue.Flags, "interface")
 		}
 		fields = append(fields, fieldParser)
 	}
 	if exportedCount == 0 {
-		p.types[typ] = nil
+		if p.unrolling == 0 {
+			p.types[typ] = nil
+		}
 		return nil, nil
 	}
-	if isUnionType {
+	if isUnionType && isInterfaceType {
+		err := p.fieldError(ErrInvalidUnion, typ.String(), "type %s cannot be both a union and an interface type", typ.String())
+		if err := p.report(err); err != nil {
+			return nil, err
+		}
+		isInterfaceType = false
+	}
+	if isUnionType || isInterfaceType {
+		kind := "union"
+		if isInterfaceType {
+			kind = "interface"
+		}
 		for _, field := range fields {
 			if field.FieldName == "__typename" {
 				continue
 			}
 			if !field.source.Anonymous {
-				return nil, fmt.Errorf("field [%s] in union type [%s] should be an embedded struct field", field.FieldName, typ.String())
+				// 接口类型允许普通字段作为公共字段
+				if isInterfaceType {
+					continue
+				}
+				if err := p.report(p.memberError(field, "field %s in union type %s should be an embedded struct field", field.FieldName, typ.String())); err != nil {
+					return nil, err
+				}
+				continue
 			}
-			// union 分支必须是命名结构体，避免匿名结构体导致响应无法稳定反序列化
+			// 分支必须是命名结构体，避免匿名结构体导致响应无法稳定反序列化
 			if field.TypeParser == nil || field.TypeParser.source.Name() == "" {
-				return nil, fmt.Errorf("field [%s] in union type [%s] should be a named struct type", field.FieldName, typ.String())
+				if err := p.report(p.memberError(field, "field %s in %s type %s should be a named struct type", field.FieldName, kind, typ.String())); err != nil {
+					return nil, err
+				}
 			}
 		}
 	}
-	p.types[typ] = &TypeParser{
-		source: typ,
-		Fields: fields,
-		Union:  isUnionType,
-		Reused: 1,
+	typeParser.Fields, typeParser.Union, typeParser.Interface = fields, isUnionType, isInterfaceType
+	if p.unrolling == 0 {
+		p.types[typ] = typeParser
 	}
-	return p.types[typ], nil
+	return typeParser, nil
+}
+
+// structType 解开指针与切片，返回 ParseType 实际解析的类型
+func structType(typ reflect.Type) reflect.Type {
+	if typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice {
+		return typ.Elem()
+	}
+	return typ
+}
+
+// memberError 构造联合或接口类型中某个分支字段的 FieldError
+func (p *Parser) memberError(field *FieldParser, format string, args ...any) *FieldError {
+	p.segments = append(p.segments, field.pathSegment())
+	defer func() { p.segments = p.segments[:len(p.segments)-1] }()
+	return p.fieldError(ErrInvalidUnion, field.FieldName, format, args...)
 }
@@ -0,0 +1,127 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// QuoteString 按 GraphQL 规范将字符串格式化为 StringValue："、\ 与控制字符转义，
+// 非 BMP 字符输出为 UTF-16 代理对转义（如 \uD83D\uDE00），以兼容只接受 BMP 源字符的旧版解析器，无效的 UTF-8 替换为 U+FFFD
+func QuoteString(s string) string {
+	var buf strings.Builder
+	buf.Grow(len(s) + 2)
+	buf.WriteByte('"')
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			switch {
+			case r < 0x20 || r == 0x7F:
+				fmt.Fprintf(&buf, `\u%04X`, r)
+			case r > 0xFFFF:
+				r -= 0x10000
+				fmt.Fprintf(&buf, `\u%04X\u%04X`, 0xD800+(r>>10), 0xDC00+(r&0x3FF))
+			default:
+				// utf8.RuneError 同时覆盖了无效字节，统一输出为 U+FFFD
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+	return buf.String()
+}
+
+// BlockString 将字符串格式化为块字符串（"""..."""），无法原样表示时（含控制字符或 \r、首尾空行、
+// 所有行带有公共缩进等会被块字符串的取值规则改写的内容）返回 false
+func BlockString(s string) (string, bool) {
+	if !printableAsBlockString(s) {
+		return "", false
+	}
+	escaped := strings.ReplaceAll(s, `"""`, `\"""`)
+	lines := strings.Split(escaped, "\n")
+	// 除首行外各行均以空白开头（或为空行）时，首行前需要换行，否则首行之后的缩进会被当作公共缩进去除
+	forceLeadingNewLine := len(lines) > 1
+	for _, line := range lines[1:] {
+		if line != "" && line[0] != ' ' && line[0] != '\t' {
+			forceLeadingNewLine = false
+			break
+		}
+	}
+	hasTrailingTripleQuotes := strings.HasSuffix(escaped, `\"""`)
+	hasTrailingQuote := strings.HasSuffix(s, `"`) && !hasTrailingTripleQuotes
+	forceTrailingNewLine := hasTrailingQuote || strings.HasSuffix(s, `\`)
+	multipleLines := len(lines) > 1 || forceTrailingNewLine || forceLeadingNewLine || hasTrailingTripleQuotes
+	skipLeadingNewLine := len(lines) == 1 && s != "" && (s[0] == ' ' || s[0] == '\t')
+
+	var buf strings.Builder
+	buf.WriteString(`"""`)
+	if (multipleLines && !skipLeadingNewLine) || forceLeadingNewLine {
+		buf.WriteByte('\n')
+	}
+	buf.WriteString(escaped)
+	if multipleLines || forceTrailingNewLine {
+		buf.WriteByte('\n')
+	}
+	buf.WriteString(`"""`)
+	return buf.String(), true
+}
+
+// printableAsBlockString 判断字符串能否用块字符串原样表示
+func printableAsBlockString(s string) bool {
+	if s == "" {
+		return true
+	}
+	if !utf8.ValidString(s) {
+		return false
+	}
+	isEmptyLine, hasIndent, hasCommonIndent, seenNonEmptyLine := true, false, true, false
+	for _, r := range s {
+		switch {
+		case r == '\n':
+			if isEmptyLine && !seenNonEmptyLine {
+				return false // 开头的空行
+			}
+			seenNonEmptyLine, isEmptyLine, hasIndent = true, true, false
+		case r == ' ' || r == '\t':
+			hasIndent = hasIndent || isEmptyLine
+		case r < 0x20 || r == 0x7F:
+			return false // 控制字符（含 \r）
+		default:
+			hasCommonIndent = hasCommonIndent && hasIndent
+			isEmptyLine = false
+		}
+	}
+	if isEmptyLine {
+		return false // 末尾的空行
+	}
+	if hasCommonIndent && seenNonEmptyLine {
+		return false // 公共缩进
+	}
+	return true
+}
+
+// formatString 格式化字符串字面量：block 为 true 且字符串包含换行时优先输出为块字符串
+func formatString(s string, block bool) string {
+	if block && strings.Contains(s, "\n") {
+		if quoted, ok := BlockString(s); ok {
+			return quoted
+		}
+	}
+	return QuoteString(s)
+}
@@ -1,20 +1,44 @@
 package core
 
-import "strings"
+import (
+	"strings"
+	"sync"
+)
 
-var indentMap = map[uint]string{
-	1: "  ",
-}
+const defaultIndentUnit = "  "
+
+var (
+	defaultIndentMu sync.RWMutex
+	defaultIndent   = defaultIndentUnit
+)
 
+// SetIndent 修改包级默认缩进，只影响之后不接受选项的 graphql.Marshal、graphql.MarshalType、graphql.MarshalWithVars 与 NewBuilder，
+// 使用 Options 的调用不受影响
+//
+// Deprecated: 使用 Options.Indent 为每次调用单独指定缩进。
 func SetIndent(val string) {
-	indentMap = map[uint]string{
-		1: val,
-	}
+	defaultIndentMu.Lock()
+	defer defaultIndentMu.Unlock()
+	defaultIndent = val
+}
+
+// DefaultIndent 返回当前的包级默认缩进（SetIndent 设置的值）
+func DefaultIndent() string {
+	defaultIndentMu.RLock()
+	defer defaultIndentMu.RUnlock()
+	return defaultIndent
+}
+
+// DefaultIndentOptions 返回使用包级默认缩进的 Options，供不接受选项的 graphql.Marshal、graphql.MarshalType、graphql.MarshalWithVars 与 NewBuilder 保留 SetIndent 的效果
+func DefaultIndentOptions() Options {
+	indent := DefaultIndent()
+	return Options{Indent: indent, NoIndent: indent == ""}
 }
-func indentWithLevel(level uint) string {
-	if v, ok := indentMap[level]; ok {
-		return v
+
+// indentWithLevel 返回指定层级的缩进字符串，缓存归属于单个 Builder，避免并发写共享状态
+func (g *Builder) indentWithLevel(level uint) string {
+	for uint(len(g.indents)) <= level {
+		g.indents = append(g.indents, strings.Repeat(g.options.Indent, len(g.indents)))
 	}
-	indentMap[level] = strings.Repeat(indentMap[1], int(level))
-	return indentMap[level]
+	return g.indents[level]
 }
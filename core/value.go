@@ -0,0 +1,499 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/lascyb/struct-to-graphql/internal/lexer"
+	"github.com/lascyb/tagkit"
+)
+
+// ObjectValue 输入对象字面量，如 {field:CREATED_AT,reverse:true}，字段按书写顺序排列
+type ObjectValue []*ObjectField
+
+// ObjectField 输入对象字面量中的一个字段
+type ObjectField struct {
+	Name  string
+	Value any
+}
+
+// ListValue 列表字面量，如 ["a","b"]
+type ListValue []any
+
+// Enum 可作为枚举值输出的类型：字面量参数、变量默认值与复合字面量中实现了该接口的值按 GraphQLEnum() 不带引号输出
+type Enum interface {
+	GraphQLEnum() string
+}
+
+// EnumValue 枚举字面量，由 tag 中的 enum(TITLE)、复合字面量与变量默认值中不带引号的名称（如 CREATED_AT）解析得到
+type EnumValue string
+
+// GraphQLEnum 实现 Enum
+func (e EnumValue) GraphQLEnum() string {
+	return string(e)
+}
+
+// VariableRef 复合字面量中的变量占位符，如 {status:$status:Status!} 中的 $status:Status!，
+// Name 为空表示匿名占位符 "$"，生成时与普通参数的变量一样注册为独立的 Variable
+type VariableRef struct {
+	Name       string
+	Type       string
+	HasDefault bool
+	DefaultVal any
+}
+
+func (o ObjectValue) String() string {
+	s, _ := formatValue(o, false)
+	return s
+}
+
+// MarshalJSON 按字段顺序输出 JSON 对象，便于将对象默认值直接作为变量值发送
+func (o ObjectValue) MarshalJSON() ([]byte, error) {
+	buf := bytes.NewBufferString("{")
+	for i, field := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(field.Name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(field.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func (l ListValue) String() string {
+	s, _ := formatValue(l, false)
+	return s
+}
+
+func (v *VariableRef) String() string {
+	return "$" + v.Name
+}
+
+// FormatValue 将 Go 值格式化为 GraphQL 值字面量，用于变量定义中的默认值：
+// nil 为 null，字符串按规范转义，实现了 Enum 的值为枚举名，ObjectValue 按字段顺序、
+// 键为字符串的 map 按键排序、结构体按字段声明顺序输出为输入对象（字段名规则与变量结构体相同，
+// json tag 的 omitempty 生效），切片与数组输出为列表，指针取其指向的值。
+// 键不是字符串的 map 与自定义了 JSON 编码的结构体（如 time.Time）无法确定输入对象的写法，返回 ErrInvalidValue
+func FormatValue(v any) (string, error) {
+	return formatValue(v, false)
+}
+
+// formatValue 格式化单个值，block 为 true 时多行字符串输出为块字符串
+func formatValue(v any, block bool) (string, error) {
+	switch val := v.(type) {
+	case nil:
+		return "null", nil
+	case *VariableRef:
+		return val.String(), nil
+	case ObjectValue:
+		parts := make([]string, len(val))
+		for i, field := range val {
+			item, err := formatValue(field.Value, block)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = field.Name + ":" + item
+		}
+		return "{" + strings.Join(parts, ",") + "}", nil
+	case json.Number:
+		return val.String(), nil
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return "null", nil
+	}
+	if enum, ok := v.(Enum); ok {
+		return enum.GraphQLEnum(), nil
+	}
+	switch rv.Kind() {
+	case reflect.Ptr:
+		return formatValue(rv.Elem().Interface(), block)
+	case reflect.String:
+		return formatString(rv.String(), block), nil
+	case reflect.Bool:
+		return strconv.FormatBool(rv.Bool()), nil
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return "null", nil
+		}
+		parts := make([]string, rv.Len())
+		for i := range parts {
+			item, err := formatValue(rv.Index(i).Interface(), block)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = item
+		}
+		return "[" + strings.Join(parts, ",") + "]", nil
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return "", NewError(ErrInvalidValue, rv.Type().String(), "map type %v cannot be written as an input object: keys must be strings", rv.Type())
+		}
+		if rv.IsNil() {
+			return "null", nil
+		}
+		keys := rv.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int { return strings.Compare(a.String(), b.String()) })
+		parts := make([]string, len(keys))
+		for i, key := range keys {
+			item, err := formatValue(rv.MapIndex(key).Interface(), block)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = key.String() + ":" + item
+		}
+		return "{" + strings.Join(parts, ",") + "}", nil
+	case reflect.Struct:
+		if customJSON(rv.Type()) {
+			return "", NewError(ErrInvalidValue, rv.Type().String(), "type %v has custom JSON encoding and cannot be written as an input object", rv.Type())
+		}
+		var parts []string
+		for i := range rv.NumField() {
+			field := rv.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, _ := variableTag(field)
+			if name == "-" || (omitEmpty(field) && rv.Field(i).IsZero()) {
+				continue
+			}
+			item, err := formatValue(rv.Field(i).Interface(), block)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, name+":"+item)
+		}
+		return "{" + strings.Join(parts, ",") + "}", nil
+	}
+	return fmt.Sprint(v), nil
+}
+
+// customJSON 判断类型（或其指针）是否自定义了 JSON 编码（json.Marshaler 或 encoding.TextMarshaler）
+func customJSON(typ reflect.Type) bool {
+	ptr := reflect.PointerTo(typ)
+	return typ.Implements(jsonMarshalerType) || ptr.Implements(jsonMarshalerType) ||
+		typ.Implements(textMarshalerType) || ptr.Implements(textMarshalerType)
+}
+
+// omitEmpty 判断字段的 json tag 是否带有 omitempty
+func omitEmpty(field reflect.StructField) bool {
+	_, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+	return slices.Contains(strings.Split(opts, ","), "omitempty")
+}
+
+// containsVariable 判断值中是否包含变量占位符
+func containsVariable(v any) bool {
+	switch val := v.(type) {
+	case *VariableRef:
+		return true
+	case ObjectValue:
+		return slices.ContainsFunc(val, func(field *ObjectField) bool { return containsVariable(field.Value) })
+	case ListValue:
+		return slices.ContainsFunc(val, containsVariable)
+	}
+	return false
+}
+
+// splitValueArgs 把 tag 首段参数列表中需要由本包解析的参数值替换为占位值后再交给 tagkit：
+// 以 "{" 或 "[" 开头的复合字面量、enum(NAME) 形式的枚举值，以及带默认值的变量（默认值可以是枚举值或复合字面量），
+// 返回替换后的 tag 与按参数名索引的解析结果
+func splitValueArgs(tag string) (string, map[string]*Arg, error) {
+	parts := splitTopLevel(tag, ',')
+	head := strings.TrimSpace(parts[0])
+	start := strings.IndexByte(head, '(')
+	if start < 0 || !strings.HasSuffix(head, ")") {
+		return tag, nil, nil
+	}
+	parsed := make(map[string]*Arg)
+	args := splitTopLevel(head[start+1:len(head)-1], ',')
+	for i, arg := range args {
+		name, raw, ok := strings.Cut(arg, ":")
+		raw = strings.TrimSpace(raw)
+		if !ok || !isParsedValue(raw) {
+			continue
+		}
+		name = strings.TrimSpace(name)
+		value, err := parseCompositeValue(raw)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid value for argument [%s]: %w", name, err)
+		}
+		if ref, ok := value.(*VariableRef); ok {
+			parsed[name] = &Arg{
+				ArgValue: tagkit.ArgValue{
+					Type:       "variable",
+					VarName:    ref.Name,
+					VarType:    ref.Type,
+					HasDefault: ref.HasDefault,
+					DefaultVal: ref.DefaultVal,
+				},
+				GraphQLType: ref.Type,
+			}
+		} else {
+			parsed[name] = &Arg{ArgValue: tagkit.ArgValue{Type: "literal", Value: value}}
+		}
+		args[i] = name + ":true"
+	}
+	if len(parsed) == 0 {
+		return tag, nil, nil
+	}
+	parts[0] = head[:start+1] + strings.Join(args, ",") + ")"
+	return strings.Join(parts, ","), parsed, nil
+}
+
+// isParsedValue 判断参数值是否需要由 parseCompositeValue 解析，其余写法沿用 tagkit 的解析结果
+func isParsedValue(raw string) bool {
+	switch {
+	case strings.HasPrefix(raw, "{"), strings.HasPrefix(raw, "["):
+		return true
+	case strings.HasPrefix(raw, "enum("):
+		return true
+	case strings.HasPrefix(raw, `"`):
+		// 双引号字符串按 GraphQL 规范处理转义（如 \n、\u00E9）
+		return true
+	case strings.HasPrefix(raw, "$"):
+		return strings.Contains(raw, "=")
+	}
+	return false
+}
+
+// parseCompositeValue 解析参数值：输入对象 {name:value}、列表 [value]、字符串、数字、true/false/null、
+// 枚举值（enum(NAME) 或不带引号的名称）以及变量占位符 $name:Type=default
+func parseCompositeValue(raw string) (any, error) {
+	p := &valueParser{src: raw}
+	value, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpace(); p.pos < len(p.src) {
+		return nil, p.errorf("unexpected %q", p.src[p.pos:])
+	}
+	return value, nil
+}
+
+type valueParser struct {
+	src string
+	pos int
+}
+
+func (p *valueParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%s (at offset %d of %q)", fmt.Sprintf(format, args...), p.pos, p.src)
+}
+
+func (p *valueParser) skipSpace() {
+	for p.pos < len(p.src) && strings.IndexByte(" \t\r\n", p.src[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+// consume 跳过空白后若下一个字符为 c 则前进并返回 true
+func (p *valueParser) consume(c byte) bool {
+	p.skipSpace()
+	if p.pos < len(p.src) && p.src[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *valueParser) name() string {
+	start := p.pos
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		if c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || p.pos > start && c >= '0' && c <= '9' {
+			p.pos++
+			continue
+		}
+		break
+	}
+	return p.src[start:p.pos]
+}
+
+func (p *valueParser) value() (any, error) {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return nil, p.errorf("unexpected end of value")
+	}
+	switch c := p.src[p.pos]; {
+	case c == '{':
+		return p.object()
+	case c == '[':
+		return p.list()
+	case c == '$':
+		return p.variable()
+	case c == '"', c == '\'':
+		return p.string()
+	case c == '-' || c >= '0' && c <= '9':
+		return p.number()
+	}
+	switch name := p.name(); name {
+	case "":
+		return nil, p.errorf("unexpected %q", p.src[p.pos:p.pos+1])
+	case "true", "false":
+		return name == "true", nil
+	case "null":
+		return nil, nil
+	case "enum":
+		if p.consume('(') {
+			p.skipSpace()
+			value := p.name()
+			if value == "" || value == "true" || value == "false" || value == "null" || !p.consume(')') {
+				return nil, p.errorf("invalid enum value, expected enum(NAME)")
+			}
+			return EnumValue(value), nil
+		}
+		return EnumValue(name), nil
+	default:
+		return EnumValue(name), nil
+	}
+}
+
+func (p *valueParser) object() (ObjectValue, error) {
+	p.pos++ // "{"
+	object := ObjectValue{}
+	seen := make(map[string]bool)
+	for !p.consume('}') {
+		if len(object) > 0 && !p.consume(',') {
+			return nil, p.errorf("expected \",\" or \"}\"")
+		}
+		p.skipSpace()
+		name := p.name()
+		if name == "" {
+			return nil, p.errorf("expected field name")
+		}
+		if seen[name] {
+			return nil, p.errorf("duplicate field %q", name)
+		}
+		seen[name] = true
+		if !p.consume(':') {
+			return nil, p.errorf("expected \":\" after field %q", name)
+		}
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		object = append(object, &ObjectField{Name: name, Value: value})
+	}
+	return object, nil
+}
+
+func (p *valueParser) list() (ListValue, error) {
+	p.pos++ // "["
+	list := ListValue{}
+	for !p.consume(']') {
+		if len(list) > 0 && !p.consume(',') {
+			return nil, p.errorf("expected \",\" or \"]\"")
+		}
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, value)
+	}
+	return list, nil
+}
+
+// string 解析双引号字符串（按 GraphQL 规范处理转义）或 tag 中沿用的单引号字符串（仅支持 \' 转义）
+func (p *valueParser) string() (string, error) {
+	start, quote := p.pos, p.src[p.pos]
+	for p.pos++; p.pos < len(p.src); p.pos++ {
+		switch p.src[p.pos] {
+		case '\\':
+			p.pos++
+		case quote:
+			p.pos++
+			raw := p.src[start:p.pos]
+			if quote == '\'' {
+				return strings.ReplaceAll(raw[1:len(raw)-1], `\'`, `'`), nil
+			}
+			tokens, err := lexer.Tokenize(raw)
+			if err != nil || len(tokens) != 1 || tokens[0].Kind != lexer.String {
+				return "", p.errorf("invalid string %s", raw)
+			}
+			return tokens[0].Value, nil
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *valueParser) number() (any, error) {
+	start := p.pos
+	for p.pos < len(p.src) && strings.IndexByte("+-.0123456789eE", p.src[p.pos]) >= 0 {
+		p.pos++
+	}
+	raw := p.src[start:p.pos]
+	if i, err := strconv.Atoi(raw); err == nil {
+		return i, nil
+	}
+	if f, err := strconv.ParseFloat(raw, 64); err == nil {
+		return f, nil
+	}
+	return nil, p.errorf("invalid number %q", raw)
+}
+
+// variable 解析 $name:Type=default，类型与默认值均可省略
+func (p *valueParser) variable() (*VariableRef, error) {
+	p.pos++ // "$"
+	ref := &VariableRef{Name: p.name()}
+	if p.consume(':') {
+		p.skipSpace()
+		typ, err := p.typeRef()
+		if err != nil {
+			return nil, err
+		}
+		ref.Type = typ
+	}
+	if p.consume('=') {
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		if containsVariable(value) {
+			return nil, p.errorf("default value of $%s cannot contain variables", ref.Name)
+		}
+		// String、ID 类型变量的默认值不带引号时仍按字符串处理，与 tagkit 的解析结果保持一致
+		if enum, ok := value.(EnumValue); ok && (namedType(ref.Type) == "String" || namedType(ref.Type) == "ID") {
+			value = string(enum)
+		}
+		ref.HasDefault, ref.DefaultVal = true, value
+	}
+	return ref, nil
+}
+
+// typeRef 解析变量类型：Name、[Type] 及其非空形式
+func (p *valueParser) typeRef() (string, error) {
+	var typ string
+	if p.consume('[') {
+		item, err := p.typeRef()
+		if err != nil {
+			return "", err
+		}
+		if !p.consume(']') {
+			return "", p.errorf("expected \"]\" in type")
+		}
+		typ = "[" + item + "]"
+	} else {
+		p.skipSpace()
+		if typ = p.name(); typ == "" {
+			return "", p.errorf("expected type name")
+		}
+	}
+	if p.consume('!') {
+		typ += "!"
+	}
+	return typ, nil
+}
@@ -0,0 +1,225 @@
+package core
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// VariableValue 变量结构体中的一个字段：变量名（不含 "$"）、由 Go 类型推导的 GraphQL 类型与序列化后的值
+type VariableValue struct {
+	Name   string
+	Type   string
+	Value  any
+	goPath string // 变量结构体中的字段路径，用于错误定位
+}
+
+// ParseVariableValues 解析变量结构体（或其指针）的导出字段，推导每个变量的 GraphQL 类型并序列化其值。
+// 变量名取 graphql tag 中的名称，其次为 json tag 名称，否则为首字母小写的字段名；
+// 类型可用 graphql tag 的 type= 覆盖（如 `graphql:"id,type=ID!"`），否则按 Go 类型推导：
+// 非指针为非空类型（int→Int!、*string→String），切片为可空列表（[]string→[String!]），
+// 命名结构体（输入对象）与实现了 Enum 的类型使用 Go 类型名，其他命名类型按底层类型推导（type Count int→Int!），
+// 实现了 json.Marshaler 或 encoding.TextMarshaler 的类型（如 time.Time）无法推导，需要用 type= 指定
+func ParseVariableValues(v any) ([]*VariableValue, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, NewError(ErrNilInput, "", "variables struct cannot be nil")
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, NewError(ErrInvalidVariables, fmt.Sprint(reflect.TypeOf(v)), "variables must be a struct, got %v", reflect.TypeOf(v))
+	}
+	root := typeName(rv.Type())
+	values := make([]*VariableValue, 0, rv.NumField())
+	for i := range rv.NumField() {
+		field := rv.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, typ := variableTag(field)
+		if name == "-" {
+			continue
+		}
+		goPath := root + "." + field.Name
+		if typ == "" {
+			var err error
+			if typ, err = variableType(field.Type); err != nil {
+				return nil, &FieldError{GoPath: goPath, Kind: ErrInvalidVariables, Subject: "$" + name,
+					Detail: fmt.Sprintf("cannot infer the type of variable $%s", name), Err: err}
+			}
+		}
+		value, err := normalizeVariableValue(rv.Field(i).Interface())
+		if err != nil {
+			return nil, &FieldError{GoPath: goPath, Kind: ErrInvalidVariables, Subject: "$" + name,
+				Detail: fmt.Sprintf("cannot serialize the value of variable $%s", name), Err: err}
+		}
+		values = append(values, &VariableValue{Name: name, Type: typ, Value: value, goPath: goPath})
+	}
+	return values, nil
+}
+
+// ApplyVariableTypes 用变量结构体推导的类型补全 variables 中未在 tag 中声明类型（或由 schema 推断）的变量，
+// tag 中显式声明的类型与 Go 类型不兼容时返回 ErrVariableTypeConflict（见 compatibleVariableType）；
+// 返回变量结构体中每个查询未使用的变量以及每个类型冲突对应的错误
+func ApplyVariableTypes(variables []*Variable, values []*VariableValue) []error {
+	byName := make(map[string]*Variable, len(variables))
+	for _, variable := range variables {
+		byName[strings.TrimPrefix(variable.Name, "$")] = variable
+	}
+	var errs []error
+	for _, value := range values {
+		variable, ok := byName[value.Name]
+		if !ok {
+			err := NewError(ErrUnusedVariable, "$"+value.Name, "variable $%s is not used in the query", value.Name)
+			err.GoPath = value.goPath
+			errs = append(errs, err)
+			continue
+		}
+		if variable.Type == "" || variable.inferred {
+			variable.Type, variable.inferred = value.Type, false
+			continue
+		}
+		if !compatibleVariableType(variable.Type, value.Type, true) {
+			err := NewError(ErrVariableTypeConflict, variable.Name, "variable %s is declared with conflicting types %s and %s", variable.Name, variable.Type, value.Type)
+			err.GoPath = value.goPath
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// builtinScalars GraphQL 内置标量类型
+var builtinScalars = map[string]bool{"Int": true, "Float": true, "String": true, "Boolean": true, "ID": true}
+
+// compatibleVariableType 判断 tag 中声明的类型 declared 能否接收由 Go 类型推导的类型 actual。
+// 最外层的可空性不比较（非空变量的 null 值由 Bind 检查），列表元素只允许 Go 一侧更严格（非空的值可以传给可空类型）；
+// 命名类型按 GraphQL 的输入转换比较：ID 接收 String 与 Int，Float 接收 Int，
+// 自定义标量与枚举等非内置类型接收内置标量的值（如以 string 表示的 DateTime）
+func compatibleVariableType(declared, actual string, top bool) bool {
+	if !top && strings.HasSuffix(declared, "!") && !strings.HasSuffix(actual, "!") {
+		return false
+	}
+	declared, actual = strings.TrimSuffix(declared, "!"), strings.TrimSuffix(actual, "!")
+	declaredItem, actualItem := listItemType(declared), listItemType(actual)
+	switch {
+	case declaredItem != "" && actualItem != "":
+		return compatibleVariableType(declaredItem, actualItem, false)
+	case declaredItem != "" || actualItem != "":
+		return false
+	case declared == actual:
+		return true
+	case declared == "ID":
+		return actual == "String" || actual == "Int"
+	case declared == "Float":
+		return actual == "Int"
+	case builtinScalars[declared]:
+		return false
+	}
+	return builtinScalars[actual]
+}
+
+// variableTag 读取变量字段的名称与 type= 覆盖
+func variableTag(field reflect.StructField) (name, typ string) {
+	if tag, ok := field.Tag.Lookup("graphql"); ok {
+		parts := splitTopLevel(tag, ',')
+		name = strings.TrimSpace(parts[0])
+		for _, part := range parts[1:] {
+			if t, ok := strings.CutPrefix(strings.TrimSpace(part), "type="); ok {
+				typ = strings.TrimSpace(t)
+			}
+		}
+	}
+	if name == "" {
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			name, _, _ = strings.Cut(tag, ",")
+		}
+	}
+	if name == "" {
+		runes := []rune(field.Name)
+		runes[0] = unicode.ToLower(runes[0])
+		name = string(runes)
+	}
+	return name, typ
+}
+
+var (
+	enumType          = reflect.TypeFor[Enum]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// variableType 由 Go 类型推导 GraphQL 输入类型：命名结构体与实现了 Enum 的类型使用 Go 类型名，
+// 其他命名类型（如 type Count int）按底层类型推导，自定义了 JSON 编码的类型需要用 type= 指定
+func variableType(typ reflect.Type) (string, error) {
+	nullable := false
+	for typ.Kind() == reflect.Ptr {
+		nullable = true
+		typ = typ.Elem()
+	}
+	var name string
+	switch {
+	case typ.Implements(enumType) || reflect.PointerTo(typ).Implements(enumType):
+		name = typ.Name()
+	case customJSON(typ):
+		// 自定义序列化的类型（如 time.Time、json.RawMessage）无法确定对应的 GraphQL 类型
+		return "", fmt.Errorf("type %v has custom JSON encoding, use type= to specify it", typ)
+	case typ.Kind() == reflect.Struct && typ.Name() != "":
+		name = typ.Name()
+	case typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Uint8:
+		// []byte 由 encoding/json 编码为 base64 字符串
+		name, nullable = "String", true
+	case typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array:
+		elem, err := variableType(typ.Elem())
+		if err != nil {
+			return "", err
+		}
+		name = "[" + elem + "]"
+		nullable = nullable || typ.Kind() == reflect.Slice
+	default:
+		switch typ.Kind() {
+		case reflect.Bool:
+			name = "Boolean"
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			name = "Int"
+		case reflect.Float32, reflect.Float64:
+			name = "Float"
+		case reflect.String:
+			name = "String"
+		default:
+			return "", fmt.Errorf("unsupported Go type %v, use type= to specify it", typ)
+		}
+	}
+	if !nullable {
+		name += "!"
+	}
+	return name, nil
+}
+
+// normalizeVariableValue 经 encoding/json 往返得到可直接发送的值（遵循输入结构体的 json tag），数字保留为 json.Number；
+// 实现了 Enum 的值按枚举名发送
+func normalizeVariableValue(v any) (any, error) {
+	if enum, ok := v.(Enum); ok {
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+			return nil, nil
+		}
+		return enum.GraphQLEnum(), nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
@@ -0,0 +1,41 @@
+package graphql
+
+import "github.com/lascyb/struct-to-graphql/core"
+
+// 错误类别，可通过 errors.Is 判断，如 errors.Is(err, graphql.ErrVariableTypeConflict)；
+// 携带字段路径的错误可通过 errors.As 取出 *FieldError
+var (
+	ErrNilInput             = core.ErrNilInput
+	ErrNotStruct            = core.ErrNotStruct
+	ErrCircularReference    = core.ErrCircularReference
+	ErrInvalidUnion         = core.ErrInvalidUnion
+	ErrInvalidTag           = core.ErrInvalidTag
+	ErrVariableTypeConflict = core.ErrVariableTypeConflict
+	ErrMissingVariableType  = core.ErrMissingVariableType
+	ErrInvalidVariables     = core.ErrInvalidVariables
+	ErrUnusedVariable       = core.ErrUnusedVariable
+	ErrUnknownVariable      = core.ErrUnknownVariable
+	ErrAmbiguousPath        = core.ErrAmbiguousPath
+	ErrDuplicateValue       = core.ErrDuplicateValue
+	ErrNullValue            = core.ErrNullValue
+	ErrInvalidValue         = core.ErrInvalidValue
+	ErrMissingValue         = core.ErrMissingValue
+	ErrTypeMismatch         = core.ErrTypeMismatch
+	ErrDecode               = core.ErrDecode
+)
+
+// FieldError 携带 Go 字段路径（GoPath）、GraphQL 响应路径（GraphQLPath）与错误类别（Kind）的错误
+type FieldError = core.FieldError
+
+// ErrorFormatter 自定义 FieldError 的错误信息，返回空字符串时使用默认的英文信息
+type ErrorFormatter = core.ErrorFormatter
+
+// FieldErrors 展开 errors.Join 合并的错误（如 Options.AllErrors 返回的错误），按顺序返回其中的全部 *FieldError
+func FieldErrors(err error) []*FieldError {
+	return core.FieldErrors(err)
+}
+
+// SetErrorFormatter 设置全局的错误信息格式化函数（如本地化），传入 nil 恢复默认的英文信息
+func SetErrorFormatter(f ErrorFormatter) {
+	core.SetErrorFormatter(f)
+}
@@ -0,0 +1,426 @@
+// Package lexer 实现 GraphQL 文档（含 SDL）的词法分析，遵循 GraphQL 规范（October 2021）的 Lexical Tokens 定义
+package lexer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Kind 词法单元类型
+type Kind int
+
+const (
+	EOF         Kind = iota
+	Punctuator       // ! $ & ( ) ... : = @ [ ] { | }
+	Name             // /[_A-Za-z][_0-9A-Za-z]*/
+	Int              // IntValue
+	Float            // FloatValue
+	String           // StringValue（Value 为解码后的内容）
+	BlockString      // 块字符串 """..."""（Value 为按规范去除公共缩进后的内容）
+)
+
+func (k Kind) String() string {
+	switch k {
+	case EOF:
+		return "EOF"
+	case Punctuator:
+		return "Punctuator"
+	case Name:
+		return "Name"
+	case Int:
+		return "Int"
+	case Float:
+		return "Float"
+	case String:
+		return "String"
+	case BlockString:
+		return "BlockString"
+	}
+	return "Unknown"
+}
+
+// Token 词法单元
+type Token struct {
+	Kind   Kind
+	Value  string // 字符串为解码后的值，其余为源码中的原文
+	Line   int    // 起始行（从 1 开始）
+	Column int    // 起始列（从 1 开始，按 rune 计）
+}
+
+func (t Token) String() string {
+	if t.Kind == EOF {
+		return "<EOF>"
+	}
+	if t.Kind == String || t.Kind == BlockString {
+		return strconv.Quote(t.Value)
+	}
+	return t.Value
+}
+
+// Error 词法错误，携带出错位置
+type Error struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("syntax error at %d:%d: %s", e.Line, e.Column, e.Message)
+}
+
+// bom Unicode BOM，可出现在文档开头或作为忽略字符
+const bom = "\uFEFF"
+
+// Lexer GraphQL 词法分析器
+type Lexer struct {
+	src       string
+	pos       int
+	line      int
+	lineStart int // 当前行首在 src 中的字节偏移
+}
+
+// New 创建词法分析器
+func New(src string) *Lexer {
+	l := &Lexer{src: src, line: 1}
+	if strings.HasPrefix(src, bom) {
+		l.pos = len(bom)
+		l.lineStart = l.pos
+	}
+	return l
+}
+
+// Tokenize 将整个文档切分为词法单元（不含末尾的 EOF）
+func Tokenize(src string) ([]Token, error) {
+	l := New(src)
+	var tokens []Token
+	for {
+		tok, err := l.Next()
+		if err != nil {
+			return nil, err
+		}
+		if tok.Kind == EOF {
+			return tokens, nil
+		}
+		tokens = append(tokens, tok)
+	}
+}
+
+// Next 返回下一个词法单元，忽略空白、逗号、注释与行终止符
+func (l *Lexer) Next() (Token, error) {
+	l.skipIgnored()
+	tok := Token{Line: l.line, Column: l.column(l.pos)}
+	if l.pos >= len(l.src) {
+		tok.Kind = EOF
+		return tok, nil
+	}
+	c := l.src[l.pos]
+	switch {
+	case strings.IndexByte("!$&():=@[]{|}", c) >= 0:
+		tok.Kind, tok.Value = Punctuator, string(c)
+		l.pos++
+		return tok, nil
+	case c == '.':
+		if strings.HasPrefix(l.src[l.pos:], "...") {
+			tok.Kind, tok.Value = Punctuator, "..."
+			l.pos += 3
+			return tok, nil
+		}
+		return tok, l.errorf(l.pos, "unexpected character %q", c)
+	case isNameStart(c):
+		start := l.pos
+		for l.pos < len(l.src) && isNameContinue(l.src[l.pos]) {
+			l.pos++
+		}
+		tok.Kind, tok.Value = Name, l.src[start:l.pos]
+		return tok, nil
+	case c == '-' || isDigit(c):
+		return l.readNumber(tok)
+	case c == '"':
+		if strings.HasPrefix(l.src[l.pos:], `"""`) {
+			return l.readBlockString(tok)
+		}
+		return l.readString(tok)
+	}
+	r, _ := utf8.DecodeRuneInString(l.src[l.pos:])
+	return tok, l.errorf(l.pos, "unexpected character %q", r)
+}
+
+func (l *Lexer) skipIgnored() {
+	for l.pos < len(l.src) {
+		switch c := l.src[l.pos]; c {
+		case ' ', '\t', ',':
+			l.pos++
+		case '\n':
+			l.pos++
+			l.newLine()
+		case '\r':
+			l.pos++
+			if l.pos < len(l.src) && l.src[l.pos] == '\n' {
+				l.pos++
+			}
+			l.newLine()
+		case '#':
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' && l.src[l.pos] != '\r' {
+				l.pos++
+			}
+		default:
+			if strings.HasPrefix(l.src[l.pos:], bom) {
+				l.pos += len(bom)
+				continue
+			}
+			return
+		}
+	}
+}
+
+func (l *Lexer) newLine() {
+	l.line++
+	l.lineStart = l.pos
+}
+
+func (l *Lexer) column(pos int) int {
+	return utf8.RuneCountInString(l.src[l.lineStart:pos]) + 1
+}
+
+func (l *Lexer) errorf(pos int, format string, args ...any) error {
+	return &Error{Line: l.line, Column: l.column(pos), Message: fmt.Sprintf(format, args...)}
+}
+
+func (l *Lexer) readNumber(tok Token) (Token, error) {
+	start := l.pos
+	if l.src[l.pos] == '-' {
+		l.pos++
+	}
+	if l.pos >= len(l.src) || !isDigit(l.src[l.pos]) {
+		return tok, l.errorf(l.pos, "invalid number, expected digit")
+	}
+	if l.src[l.pos] == '0' {
+		l.pos++
+		if l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+			return tok, l.errorf(l.pos, "invalid number, unexpected digit after 0")
+		}
+	} else {
+		l.skipDigits()
+	}
+	tok.Kind = Int
+	if l.pos < len(l.src) && l.src[l.pos] == '.' {
+		tok.Kind = Float
+		l.pos++
+		if l.pos >= len(l.src) || !isDigit(l.src[l.pos]) {
+			return tok, l.errorf(l.pos, "invalid number, expected digit after '.'")
+		}
+		l.skipDigits()
+	}
+	if l.pos < len(l.src) && (l.src[l.pos] == 'e' || l.src[l.pos] == 'E') {
+		tok.Kind = Float
+		l.pos++
+		if l.pos < len(l.src) && (l.src[l.pos] == '+' || l.src[l.pos] == '-') {
+			l.pos++
+		}
+		if l.pos >= len(l.src) || !isDigit(l.src[l.pos]) {
+			return tok, l.errorf(l.pos, "invalid number, expected digit in exponent")
+		}
+		l.skipDigits()
+	}
+	if l.pos < len(l.src) && (l.src[l.pos] == '.' || isNameStart(l.src[l.pos])) {
+		return tok, l.errorf(l.pos, "invalid number, unexpected character %q", l.src[l.pos])
+	}
+	tok.Value = l.src[start:l.pos]
+	return tok, nil
+}
+
+func (l *Lexer) skipDigits() {
+	for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+		l.pos++
+	}
+}
+
+func (l *Lexer) readString(tok Token) (Token, error) {
+	l.pos++ // 开头的 "
+	var buf strings.Builder
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == '"':
+			l.pos++
+			tok.Kind, tok.Value = String, buf.String()
+			return tok, nil
+		case c == '\n' || c == '\r':
+			return tok, l.errorf(l.pos, "unterminated string")
+		case c == '\\':
+			r, err := l.readEscape()
+			if err != nil {
+				return tok, err
+			}
+			buf.WriteRune(r)
+		case c < 0x20 && c != '\t':
+			return tok, l.errorf(l.pos, "invalid character within string: %U", rune(c))
+		default:
+			r, size := utf8.DecodeRuneInString(l.src[l.pos:])
+			if r == utf8.RuneError && size <= 1 {
+				return tok, l.errorf(l.pos, "invalid UTF-8 within string")
+			}
+			buf.WriteRune(r)
+			l.pos += size
+		}
+	}
+	return tok, l.errorf(l.pos, "unterminated string")
+}
+
+// readEscape 读取字符串中的转义序列，支持 \uXXXX、\u{...} 与 UTF-16 代理对
+func (l *Lexer) readEscape() (rune, error) {
+	start := l.pos
+	l.pos++ // 反斜杠
+	if l.pos >= len(l.src) {
+		return 0, l.errorf(start, "unterminated string")
+	}
+	c := l.src[l.pos]
+	l.pos++
+	switch c {
+	case '"', '\\', '/':
+		return rune(c), nil
+	case 'b':
+		return '\b', nil
+	case 'f':
+		return '\f', nil
+	case 'n':
+		return '\n', nil
+	case 'r':
+		return '\r', nil
+	case 't':
+		return '\t', nil
+	case 'u':
+		r, err := l.readUnicode(start)
+		if err != nil {
+			return 0, err
+		}
+		if utf16.IsSurrogate(r) {
+			// 高位代理必须紧跟低位代理
+			if r < 0xDC00 && strings.HasPrefix(l.src[l.pos:], `\u`) {
+				next := l.pos
+				l.pos += 2
+				low, err := l.readUnicode(next)
+				if err == nil {
+					if combined := utf16.DecodeRune(r, low); combined != utf8.RuneError {
+						return combined, nil
+					}
+				}
+			}
+			return 0, l.errorf(start, "invalid Unicode escape sequence: unpaired surrogate")
+		}
+		return r, nil
+	}
+	return 0, l.errorf(start, "invalid escape sequence \\%c", c)
+}
+
+func (l *Lexer) readUnicode(start int) (rune, error) {
+	if l.pos < len(l.src) && l.src[l.pos] == '{' {
+		end := strings.IndexByte(l.src[l.pos:], '}')
+		if end < 0 {
+			return 0, l.errorf(start, "invalid Unicode escape sequence")
+		}
+		v, err := strconv.ParseUint(l.src[l.pos+1:l.pos+end], 16, 32)
+		if err != nil || v > utf8.MaxRune || utf16.IsSurrogate(rune(v)) {
+			return 0, l.errorf(start, "invalid Unicode escape sequence")
+		}
+		l.pos += end + 1
+		return rune(v), nil
+	}
+	if l.pos+4 > len(l.src) {
+		return 0, l.errorf(start, "invalid Unicode escape sequence")
+	}
+	v, err := strconv.ParseUint(l.src[l.pos:l.pos+4], 16, 32)
+	if err != nil {
+		return 0, l.errorf(start, "invalid Unicode escape sequence")
+	}
+	l.pos += 4
+	return rune(v), nil
+}
+
+func (l *Lexer) readBlockString(tok Token) (Token, error) {
+	l.pos += 3
+	var raw strings.Builder
+	for l.pos < len(l.src) {
+		switch {
+		case strings.HasPrefix(l.src[l.pos:], `"""`):
+			l.pos += 3
+			tok.Kind, tok.Value = BlockString, BlockStringValue(raw.String())
+			return tok, nil
+		case strings.HasPrefix(l.src[l.pos:], `\"""`):
+			raw.WriteString(`"""`)
+			l.pos += 4
+		case l.src[l.pos] == '\n':
+			raw.WriteByte('\n')
+			l.pos++
+			l.newLine()
+		case l.src[l.pos] == '\r':
+			raw.WriteByte('\n')
+			l.pos++
+			if l.pos < len(l.src) && l.src[l.pos] == '\n' {
+				l.pos++
+			}
+			l.newLine()
+		case l.src[l.pos] < 0x20 && l.src[l.pos] != '\t':
+			return tok, l.errorf(l.pos, "invalid character within string: %U", rune(l.src[l.pos]))
+		default:
+			r, size := utf8.DecodeRuneInString(l.src[l.pos:])
+			if r == utf8.RuneError && size <= 1 {
+				return tok, l.errorf(l.pos, "invalid UTF-8 within string")
+			}
+			raw.WriteString(l.src[l.pos : l.pos+size])
+			l.pos += size
+		}
+	}
+	return tok, l.errorf(l.pos, "unterminated block string")
+}
+
+// BlockStringValue 按规范计算块字符串的值：去除公共缩进以及首尾的空白行
+func BlockStringValue(raw string) string {
+	lines := strings.Split(raw, "\n")
+	commonIndent := -1
+	for _, line := range lines[1:] {
+		indent := leadingWhitespace(line)
+		if indent < len(line) && (commonIndent < 0 || indent < commonIndent) {
+			commonIndent = indent
+		}
+	}
+	if commonIndent > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) >= commonIndent {
+				lines[i] = lines[i][commonIndent:]
+			} else {
+				lines[i] = ""
+			}
+		}
+	}
+	for len(lines) > 0 && leadingWhitespace(lines[0]) == len(lines[0]) {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && leadingWhitespace(lines[len(lines)-1]) == len(lines[len(lines)-1]) {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func leadingWhitespace(s string) int {
+	i := 0
+	for i < len(s) && (s[i] == ' ' || s[i] == '\t') {
+		i++
+	}
+	return i
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isNameContinue(c byte) bool {
+	return isNameStart(c) || isDigit(c)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
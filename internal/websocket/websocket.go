@@ -0,0 +1,421 @@
+// Package websocket 实现本模块所需的最小 RFC 6455 子集：握手、文本/二进制消息、分片、ping/pong 与关闭握手。
+// 客户端（Dial）供 graphql-transport-ws 订阅使用，服务端（Accept）供进程内测试服务器使用。
+package websocket
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 消息（帧）类型
+const (
+	TextMessage   = 1
+	BinaryMessage = 2
+	CloseMessage  = 8
+	PingMessage   = 9
+	PongMessage   = 10
+)
+
+// 关闭状态码
+const (
+	CloseNormalClosure    = 1000
+	CloseProtocolError    = 1002
+	CloseNoStatusReceived = 1005
+)
+
+const (
+	acceptGUID     = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	maxMessageSize = 32 << 20
+)
+
+// ErrClosed 连接已在本端关闭
+var ErrClosed = errors.New("websocket: connection closed")
+
+// CloseError 对端发送的关闭帧
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *CloseError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("websocket: close %d", e.Code)
+	}
+	return fmt.Sprintf("websocket: close %d %s", e.Code, e.Reason)
+}
+
+// Conn WebSocket 连接，写操作可并发调用，读操作应由单个 goroutine 执行
+type Conn struct {
+	conn        net.Conn
+	br          *bufio.Reader
+	client      bool   // 客户端发送的帧必须加掩码
+	Subprotocol string // 握手协商出的子协议
+
+	writeMu   sync.Mutex
+	closeOnce sync.Once
+	closed    bool
+}
+
+// Dial 连接 ws:// 或 wss:// 地址并完成握手，subprotocols 为客户端支持的子协议（按优先级排列）；
+// ctx 在握手完成前结束时关闭连接并返回 ctx.Err()
+func Dial(ctx context.Context, rawURL string, header http.Header, subprotocols ...string) (*Conn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	port := u.Port()
+	switch u.Scheme {
+	case "ws":
+		if port == "" {
+			port = "80"
+		}
+	case "wss":
+		if port == "" {
+			port = "443"
+		}
+	default:
+		return nil, fmt.Errorf("websocket: unsupported scheme %q", u.Scheme)
+	}
+
+	var dialer net.Dialer
+	nc, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(u.Hostname(), port))
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = nc.SetDeadline(deadline)
+	}
+	// 没有截止时间的 ctx 被取消时，关闭连接以中断阻塞中的握手读写
+	stop := context.AfterFunc(ctx, func() { _ = nc.Close() })
+	conn, err := handshake(ctx, nc, u, header, subprotocols)
+	if !stop() || ctx.Err() != nil {
+		_ = nc.Close()
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		_ = nc.Close()
+		return nil, err
+	}
+	_ = nc.SetDeadline(time.Time{})
+	return conn, nil
+}
+
+// handshake 在已建立的 TCP 连接上完成 TLS（wss://）与 WebSocket 握手，出错时由调用方关闭连接
+func handshake(ctx context.Context, nc net.Conn, u *url.URL, header http.Header, subprotocols []string) (*Conn, error) {
+	if u.Scheme == "wss" {
+		tlsConn := tls.Client(nc, &tls.Config{ServerName: u.Hostname()})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return nil, err
+		}
+		nc = tlsConn
+	}
+
+	keyBytes := make([]byte, 16)
+	if _, err := rand.Read(keyBytes); err != nil {
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(keyBytes)
+
+	req := &http.Request{
+		Method:     http.MethodGet,
+		URL:        u,
+		Host:       u.Host,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     make(http.Header),
+	}
+	for k, v := range header {
+		req.Header[k] = append([]string(nil), v...)
+	}
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	if len(subprotocols) > 0 {
+		req.Header.Set("Sec-WebSocket-Protocol", strings.Join(subprotocols, ", "))
+	}
+	if err := req.Write(nc); err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReader(nc)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		return nil, err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		return nil, fmt.Errorf("websocket: bad handshake status %s", resp.Status)
+	}
+	if resp.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		return nil, errors.New("websocket: invalid Sec-WebSocket-Accept")
+	}
+	protocol := resp.Header.Get("Sec-WebSocket-Protocol")
+	if len(subprotocols) > 0 && !slices.Contains(subprotocols, protocol) {
+		return nil, fmt.Errorf("websocket: server selected unsupported subprotocol %q", protocol)
+	}
+	return &Conn{conn: nc, br: br, client: true, Subprotocol: protocol}, nil
+}
+
+// Accept 在 HTTP 处理函数中完成服务端握手，选用客户端请求中第一个被服务端支持的子协议
+func Accept(w http.ResponseWriter, r *http.Request, subprotocols ...string) (*Conn, error) {
+	if !headerContains(r.Header, "Connection", "upgrade") || !headerContains(r.Header, "Upgrade", "websocket") {
+		http.Error(w, "websocket: not a websocket handshake", http.StatusBadRequest)
+		return nil, errors.New("websocket: not a websocket handshake")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" || r.Header.Get("Sec-WebSocket-Version") != "13" {
+		http.Error(w, "websocket: unsupported handshake", http.StatusBadRequest)
+		return nil, errors.New("websocket: unsupported handshake")
+	}
+	protocol := ""
+	for _, p := range strings.Split(r.Header.Get("Sec-WebSocket-Protocol"), ",") {
+		if p = strings.TrimSpace(p); p != "" && slices.Contains(subprotocols, p) {
+			protocol = p
+			break
+		}
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "websocket: response does not support hijacking", http.StatusInternalServerError)
+		return nil, errors.New("websocket: response does not support hijacking")
+	}
+	nc, brw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, err
+	}
+	var buf strings.Builder
+	buf.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n")
+	buf.WriteString("Sec-WebSocket-Accept: " + acceptKey(key) + "\r\n")
+	if protocol != "" {
+		buf.WriteString("Sec-WebSocket-Protocol: " + protocol + "\r\n")
+	}
+	buf.WriteString("\r\n")
+	if _, err := nc.Write([]byte(buf.String())); err != nil {
+		_ = nc.Close()
+		return nil, err
+	}
+	return &Conn{conn: nc, br: brw.Reader, Subprotocol: protocol}, nil
+}
+
+// ReadMessage 读取下一条完整的数据消息；ping 自动回复 pong，收到关闭帧时回应关闭并返回 *CloseError
+func (c *Conn) ReadMessage() (int, []byte, error) {
+	var (
+		messageType int
+		message     []byte
+	)
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+		switch opcode {
+		case PingMessage:
+			if err := c.WriteMessage(PongMessage, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case PongMessage:
+			continue
+		case CloseMessage:
+			closeErr := &CloseError{Code: CloseNoStatusReceived}
+			if len(payload) >= 2 {
+				closeErr.Code = int(binary.BigEndian.Uint16(payload))
+				closeErr.Reason = string(payload[2:])
+			}
+			// 1005 只表示未收到状态码，不能出现在关闭帧中，此时回应不带状态码的关闭帧
+			_ = c.writeClose(closeErr.Code, "")
+			_ = c.conn.Close()
+			return 0, nil, closeErr
+		case 0:
+			if messageType == 0 {
+				return 0, nil, c.protocolError("unexpected continuation frame")
+			}
+		case TextMessage, BinaryMessage:
+			if messageType != 0 {
+				return 0, nil, c.protocolError("expected continuation frame")
+			}
+			messageType = opcode
+		default:
+			return 0, nil, c.protocolError(fmt.Sprintf("unknown opcode %d", opcode))
+		}
+		if len(message)+len(payload) > maxMessageSize {
+			return 0, nil, c.protocolError("message too large")
+		}
+		message = append(message, payload...)
+		if fin {
+			return messageType, message, nil
+		}
+	}
+}
+
+// WriteMessage 以单帧发送一条消息
+func (c *Conn) WriteMessage(messageType int, data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.closed {
+		return ErrClosed
+	}
+	return c.writeFrame(messageType, data)
+}
+
+// Close 发送正常关闭帧并关闭底层连接，可重复调用
+func (c *Conn) Close() error {
+	return c.CloseWithCode(CloseNormalClosure, "")
+}
+
+// CloseWithCode 发送指定状态码的关闭帧并关闭底层连接，可重复调用
+func (c *Conn) CloseWithCode(code int, reason string) error {
+	var err error
+	c.closeOnce.Do(func() {
+		_ = c.writeClose(code, reason)
+		err = c.conn.Close()
+	})
+	return err
+}
+
+func (c *Conn) writeClose(code int, reason string) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	if code == CloseNoStatusReceived {
+		return c.writeFrame(CloseMessage, nil)
+	}
+	payload := make([]byte, 2, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	payload = append(payload, reason...)
+	return c.writeFrame(CloseMessage, payload)
+}
+
+func (c *Conn) protocolError(message string) error {
+	_ = c.CloseWithCode(CloseProtocolError, message)
+	return fmt.Errorf("websocket: %s", message)
+}
+
+func (c *Conn) writeFrame(opcode int, data []byte) error {
+	header := make([]byte, 0, 14)
+	header = append(header, 0x80|byte(opcode))
+	var maskBit byte
+	if c.client {
+		maskBit = 0x80
+	}
+	switch n := len(data); {
+	case n < 126:
+		header = append(header, maskBit|byte(n))
+	case n <= 0xFFFF:
+		header = append(header, maskBit|126)
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header = append(header, maskBit|127)
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+	payload := data
+	if c.client {
+		var mask [4]byte
+		if _, err := rand.Read(mask[:]); err != nil {
+			return err
+		}
+		header = append(header, mask[:]...)
+		payload = make([]byte, len(data))
+		for i := range data {
+			payload[i] = data[i] ^ mask[i%4]
+		}
+	}
+	if _, err := c.conn.Write(append(header, payload...)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// readFrame 读取一帧，按 RFC 6455 §5.1、§5.2、§5.5 拒绝未协商扩展却设置了 RSV 位的帧、
+// 方向不符的掩码（服务端发出的帧不得加掩码，客户端发出的帧必须加掩码）以及分片或超过 125 字节的控制帧
+func (c *Conn) readFrame() (bool, int, []byte, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(c.br, head[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin := head[0]&0x80 != 0
+	opcode := int(head[0] & 0x0F)
+	masked := head[1]&0x80 != 0
+	length := uint64(head[1] & 0x7F)
+	switch {
+	case head[0]&0x70 != 0:
+		return false, 0, nil, c.protocolError("reserved bits set")
+	case c.client && masked:
+		return false, 0, nil, c.protocolError("masked frame from server")
+	case !c.client && !masked:
+		return false, 0, nil, c.protocolError("unmasked frame from client")
+	case opcode >= CloseMessage && !fin:
+		return false, 0, nil, c.protocolError("fragmented control frame")
+	case opcode >= CloseMessage && length > 125:
+		return false, 0, nil, c.protocolError("control frame too large")
+	}
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if length > maxMessageSize {
+		return false, 0, nil, c.protocolError("frame too large")
+	}
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(c.br, mask[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return fin, opcode, payload, nil
+}
+
+func acceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func headerContains(header http.Header, name, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
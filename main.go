@@ -3,10 +3,10 @@ package graphql
 import (
 	"errors"
 	"fmt"
-	"maps"
 	"reflect"
 	"slices"
 	"strings"
+	"sync"
 
 	"github.com/lascyb/struct-to-graphql/core"
 )
@@ -16,32 +16,162 @@ type Graphql struct {
 	Body      string           // GraphQL 查询主体内容
 	Variables []*core.Variable // 层次化变量统计数组（按路径组织）
 	Fragments []*core.Fragment // 复用结构模块数组
+	Values    map[string]any   // MarshalWithVars 时由变量结构体序列化得到的变量值，可直接作为请求的 variables
+
+	typeParser *core.TypeParser // 根结构体的解析结果，用于解码响应；可能与缓存共享，只读
+	owned      *ownedTypeParser // TypeParser 返回的副本，每个 *Graphql 只复制一次
+}
+
+// ownedTypeParser 第一次调用 Graphql.TypeParser 时复制的解析结果
+type ownedTypeParser struct {
+	once       sync.Once
+	typeParser *core.TypeParser
 }
 
+// Options 单次生成使用的渲染与命名选项，见 core.Options
+type Options = core.Options
+
+// FragmentPolicy 选择集封装为 Fragment 的全局策略，见 Options.Fragments
+type FragmentPolicy = core.FragmentPolicy
+
+const (
+	FragmentAuto   = core.FragmentAuto   // 被多次引用的命名结构体封装为 Fragment（默认）
+	FragmentAlways = core.FragmentAlways // 所有字段的命名结构体选择集都封装为 Fragment
+	FragmentNever  = core.FragmentNever  // 只有带 fragment 标记的字段封装为 Fragment
+)
+
+// Schema 推断变量类型所需的 schema 信息，见 core.Schema（*schema.Schema 实现了该接口）
+type Schema = core.Schema
+
+// Enum 按枚举值（不带引号）输出的类型，见 core.Enum；可用于变量默认值（Variable.DefaultValue）与变量结构体的取值
+type Enum = core.Enum
+
+// EnumValue 枚举字面量，实现了 Enum，见 core.EnumValue
+type EnumValue = core.EnumValue
+
+// ObjectValue 有序的输入对象值（键值对按书写顺序输出），可用作对象类型的变量默认值，见 core.ObjectValue
+type ObjectValue = core.ObjectValue
+
+// ObjectField ObjectValue 中的一个键值对
+type ObjectField = core.ObjectField
+
+// Marshal 使用默认选项将结构体转换为 GraphQL 查询，缩进为 SetIndent 设置的值（默认两个空格）
 func Marshal(v any) (*Graphql, error) {
-	if v == nil {
-		return nil, errors.New("struct to parse cannot be nil")
+	return MarshalWithOptions(v, core.DefaultIndentOptions())
+}
+
+// MarshalWithOptions 使用指定选项将结构体转换为 GraphQL 查询，选项仅作用于本次调用，可安全并发使用；
+// 同一类型与选项的生成结果会被缓存，每次返回独立的副本（见 Options.DisableCache）
+func MarshalWithOptions(v any, opts Options) (*Graphql, error) {
+	return marshal(v, nil, opts)
+}
+
+// MarshalWithVars 将 query 结构体转换为 GraphQL 查询，并由 vars 结构体（如 struct{ First int; After *string }）
+// 推导变量定义的类型（int→Int!、*string→String、切片→列表、命名结构体→输入对象名），
+// 同时把变量值序列化到 Values；tag 中显式声明的类型优先，但与 Go 类型不兼容时返回 ErrVariableTypeConflict，
+// vars 中存在查询未使用的变量时返回错误；与 Marshal 相同，缩进为 SetIndent 设置的值
+func MarshalWithVars(query, vars any) (*Graphql, error) {
+	return MarshalWithVarsOptions(query, vars, core.DefaultIndentOptions())
+}
+
+// MarshalWithVarsOptions 与 MarshalWithVars 相同，但使用指定选项（如 Schema、Indent、AllErrors）
+func MarshalWithVarsOptions(query, vars any, opts Options) (*Graphql, error) {
+	if vars == nil {
+		return nil, core.NewError(core.ErrNilInput, "", "variables struct cannot be nil")
 	}
-	parser, err := core.NewParser().ParseType(reflect.TypeOf(v))
+	values, err := core.ParseVariableValues(vars)
 	if err != nil {
 		return nil, err
 	}
+	return marshal(query, values, opts)
+}
 
-	builder := core.NewBuilder()
-	body, err := builder.Build(parser)
+func marshal(v any, values []*core.VariableValue, opts Options) (*Graphql, error) {
+	if v == nil {
+		return nil, core.NewError(core.ErrNilInput, "", "struct to parse cannot be nil")
+	}
+	return marshalType(reflect.TypeOf(v), values, opts)
+}
+
+// marshalType 生成类型 typ 的查询，values 不为空时用变量结构体补全变量类型与取值
+func marshalType(typ reflect.Type, values []*core.VariableValue, opts Options) (*Graphql, error) {
+	p := loadPlan(typ, opts)
+	if p.err != nil {
+		return nil, p.err
+	}
+	g := p.graphql.clone()
+	errs := slices.Clone(p.errs)
+	if values != nil {
+		for _, err := range core.ApplyVariableTypes(g.Variables, values) {
+			if !opts.AllErrors {
+				return nil, err
+			}
+			errs = append(errs, err)
+		}
+		g.Values = make(map[string]any, len(values))
+		for _, value := range values {
+			g.Values[value.Name] = value.Value
+		}
+	}
+	if opts.AllErrors {
+		for _, v := range g.Variables {
+			if v.Type == "" {
+				errs = append(errs, missingVariableType(v))
+			}
+		}
+		if len(errs) > 0 {
+			return nil, joinErrors(errs)
+		}
+	}
+	return g, nil
+}
+
+// TypeParser 返回根结构体的解析结果，供 schema 校验等工具遍历选择集。
+// 生成时的解析结果与缓存共享，因此返回的是属于该 *Graphql 的深拷贝（第一次调用时复制，之后返回同一个副本），
+// 修改它不影响缓存与其他 *Graphql
+func (g *Graphql) TypeParser() *core.TypeParser {
+	if g == nil || g.typeParser == nil {
+		return nil
+	}
+	if g.owned == nil {
+		return g.typeParser.Clone()
+	}
+	g.owned.once.Do(func() { g.owned.typeParser = g.typeParser.Clone() })
+	return g.owned.typeParser
+}
+
+// Unmarshal 将 GraphQL 响应中的 data 解码到 v（指向结构体的非 nil 指针），
+// 字段按生成文档时的响应键匹配，设置 alias 的字段无需在 json tag 中重复别名；
+// 未设置 depth 的自引用类型（如按 Options.MaxDepth 生成的查询）按响应数据的实际层数解码
+func Unmarshal(data []byte, v any) error {
+	if v == nil {
+		return core.NewError(core.ErrNilInput, "", "struct to decode cannot be nil")
+	}
+	parser, err := core.NewDecodeParser().ParseType(reflect.TypeOf(v))
 	if err != nil {
-		return nil, err
+		return err
 	}
+	return core.Decode(parser, data, v)
+}
 
-	return &Graphql{
-		Body:      body,
-		Variables: slices.Collect(maps.Values(builder.VariableMap)),
-		Fragments: slices.Collect(maps.Values(builder.FragmentMap)),
-	}, nil
+// Unmarshal 复用 Marshal 时的解析结果解码响应 data，v 必须指向生成该查询的结构体类型
+func (g *Graphql) Unmarshal(data []byte, v any) error {
+	if g == nil {
+		return core.NewError(core.ErrNilInput, "", "graphql cannot be nil")
+	}
+	typ := reflect.TypeOf(v)
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if g.typeParser == nil || typ != g.typeParser.Type() {
+		return core.NewError(core.ErrTypeMismatch, fmt.Sprint(reflect.TypeOf(v)), "cannot decode into %v: query was built from a different type", reflect.TypeOf(v))
+	}
+	return core.Decode(g.typeParser, data, v)
 }
+
 func (g *Graphql) build(operation, name string) (string, error) {
 	if g == nil {
-		return "", errors.New("graphql cannot be nil")
+		return "", core.NewError(core.ErrNilInput, "", "graphql cannot be nil")
 	}
 
 	var parts []string
@@ -55,11 +185,17 @@ func (g *Graphql) build(operation, name string) (string, error) {
 	varDefs := make([]string, 0, len(g.Variables))
 	for _, v := range g.Variables {
 		if v.Type == "" {
-			return "", fmt.Errorf("变量 %s 缺少类型定义", v.Name)
+			return "", missingVariableType(v)
 		}
 		def := fmt.Sprintf("%s:%s", v.Name, v.Type)
 		if v.HasDefault {
-			def += "=" + formatVariableDefault(v.DefaultValue)
+			value, err := core.FormatValue(v.DefaultValue)
+			if err != nil {
+				invalid := core.VariableError(v, core.ErrInvalidValue, "invalid default value for variable %s", v.Name)
+				invalid.Err = err
+				return "", invalid
+			}
+			def += "=" + value
 		}
 		varDefs = append(varDefs, def)
 	}
@@ -94,29 +230,46 @@ func (g *Graphql) Mutation(name string) (string, error) {
 	return g.build("mutation", name)
 }
 
-// formatVariableDefault 将变量默认值格式化为 GraphQL 变量定义中的写法（如 "value"、123、[1,2,3]）
-func formatVariableDefault(v interface{}) string {
-	if v == nil {
-		return "null"
-	}
-	switch val := v.(type) {
-	case string:
-		return `"` + strings.ReplaceAll(val, `"`, `\"`) + `"`
-	case bool:
-		if val {
-			return "true"
-		}
-		return "false"
-	case []interface{}:
-		parts := make([]string, 0, len(val))
-		for _, e := range val {
-			parts = append(parts, formatVariableDefault(e))
-		}
-		return "[" + strings.Join(parts, ", ") + "]"
-	default:
-		return fmt.Sprint(v)
-	}
+// Subscription 组装完整的 GraphQL 订阅字符串
+// name: 订阅名称，如 "OnCommentAdded" 等
+// 返回: 完整的 GraphQL 订阅字符串，包含操作声明、变量定义、订阅体和 Fragments
+func (g *Graphql) Subscription(name string) (string, error) {
+	return g.build("subscription", name)
 }
+
+// SetIndent 修改 Marshal、MarshalType、MarshalWithVars 使用的默认缩进，MarshalWithOptions 等接受选项的调用不受影响
+//
+// Deprecated: 全局设置会影响所有 Marshal 调用，请使用 MarshalWithOptions 并设置 Options.Indent。
 func SetIndent(val string) {
 	core.SetIndent(val)
 }
+
+// missingVariableType 变量未声明类型且无法推断时的错误
+func missingVariableType(v *core.Variable) error {
+	return core.VariableError(v, core.ErrMissingVariableType, "variable %s has no type; declare it in the tag (e.g. $id:ID!), set Options.Schema or use MarshalWithVars", v.Name)
+}
+
+// joinErrors 合并 Options.AllErrors 收集的错误：解析与生成阶段对同一字段、同一对象的同类问题（如匿名结构体联合分支）只保留先发现的一个；
+// 只有一个错误时原样返回
+func joinErrors(errs []error) error {
+	type key struct {
+		kind          error
+		path, subject string
+	}
+	seen := make(map[key]bool)
+	result := make([]error, 0, len(errs))
+	for _, err := range errs {
+		if fieldErr, ok := err.(*core.FieldError); ok && fieldErr.GoPath != "" {
+			k := key{fieldErr.Kind, fieldErr.GoPath, fieldErr.Subject}
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+		}
+		result = append(result, err)
+	}
+	if len(result) == 1 {
+		return result[0]
+	}
+	return errors.Join(result...)
+}
@@ -0,0 +1,38 @@
+package graphql
+
+import (
+	"reflect"
+
+	"github.com/lascyb/struct-to-graphql/core"
+)
+
+// Operation 由结构体类型 T 生成的查询，嵌入 *Graphql（Query、Bind 等方法均可直接使用），
+// Decode 直接把响应解码为 T，便于执行器、解码与缓存在类型上保持一致
+type Operation[T any] struct {
+	*Graphql
+}
+
+// MarshalType 使用默认选项按类型参数 T 生成 GraphQL 查询，无需构造 T 的零值，如 graphql.MarshalType[ProductQuery]()；
+// 与 Marshal 相同，缩进为 SetIndent 设置的值
+func MarshalType[T any]() (*Operation[T], error) {
+	return MarshalTypeWithOptions[T](core.DefaultIndentOptions())
+}
+
+// MarshalTypeWithOptions 使用指定选项按类型参数 T 生成 GraphQL 查询，T 可以是结构体或指向结构体的指针
+func MarshalTypeWithOptions[T any](opts Options) (*Operation[T], error) {
+	g, err := marshalType(reflect.TypeFor[T](), nil, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Operation[T]{Graphql: g}, nil
+}
+
+// Decode 按生成文档时的响应键将 GraphQL 响应中的 data 解码为 T
+func (o *Operation[T]) Decode(data []byte) (T, error) {
+	var value T
+	if o == nil || o.Graphql == nil {
+		return value, core.NewError(core.ErrNilInput, "", "operation cannot be nil")
+	}
+	err := core.Decode(o.typeParser, data, &value)
+	return value, err
+}
@@ -0,0 +1,250 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	graphql "github.com/lascyb/struct-to-graphql"
+)
+
+// IntrospectionQuery 返回标准内省查询（与 graphql-js getIntrospectionQuery 的默认输出等价，另外查询指令的 isRepeatable），
+// 查询由本库根据结构体生成；执行结果可交给 ParseIntrospection 加载
+func IntrospectionQuery() (string, error) {
+	g, err := graphql.Marshal(introspectionQuery{})
+	if err != nil {
+		return "", err
+	}
+	return g.Query("IntrospectionQuery")
+}
+
+// introspectionQuery 内省查询的选择集；复用的 __Type / __InputValue 由 fragment 标记生成 Fragment，类型条件由 type= 显式指定，
+// ofType 链与 types 带 nofragment 始终直接展开，生成的查询不依赖 Go 类型名与 Options.Fragments
+type introspectionQuery struct {
+	Schema struct {
+		QueryType struct {
+			Name string `graphql:"name"`
+		} `graphql:"queryType"`
+		MutationType struct {
+			Name string `graphql:"name"`
+		} `graphql:"mutationType"`
+		SubscriptionType struct {
+			Name string `graphql:"name"`
+		} `graphql:"subscriptionType"`
+		Types      []introspectionFullType `graphql:"types,nofragment"`
+		Directives []struct {
+			Name         string         `graphql:"name"`
+			Description  string         `graphql:"description"`
+			Locations    []string       `graphql:"locations"`
+			Args         []__InputValue `graphql:"args,fragment,type=__InputValue"`
+			IsRepeatable bool           `graphql:"isRepeatable"`
+		} `graphql:"directives"`
+	} `graphql:"__schema"`
+}
+
+type introspectionFullType struct {
+	Kind        string `graphql:"kind"`
+	Name        string `graphql:"name"`
+	Description string `graphql:"description"`
+	Fields      []struct {
+		Name              string         `graphql:"name"`
+		Description       string         `graphql:"description"`
+		Args              []__InputValue `graphql:"args,fragment,type=__InputValue"`
+		Type              __Type         `graphql:"type,fragment,type=__Type"`
+		IsDeprecated      bool           `graphql:"isDeprecated"`
+		DeprecationReason string         `graphql:"deprecationReason"`
+	} `graphql:"fields(includeDeprecated:true)"`
+	InputFields []__InputValue `graphql:"inputFields,fragment,type=__InputValue"`
+	Interfaces  []__Type       `graphql:"interfaces,fragment,type=__Type"`
+	EnumValues  []struct {
+		Name              string `graphql:"name"`
+		Description       string `graphql:"description"`
+		IsDeprecated      bool   `graphql:"isDeprecated"`
+		DeprecationReason string `graphql:"deprecationReason"`
+	} `graphql:"enumValues(includeDeprecated:true)"`
+	PossibleTypes []__Type `graphql:"possibleTypes,fragment,type=__Type"`
+}
+
+type __InputValue struct {
+	Name         string `graphql:"name"`
+	Description  string `graphql:"description"`
+	Type         __Type `graphql:"type,fragment,type=__Type"`
+	DefaultValue string `graphql:"defaultValue"`
+}
+
+// __Type 类型引用，ofType 展开 7 层，足以描述 [[Int!]!]! 等常见的多层包装
+type __Type struct {
+	Kind   string  `graphql:"kind"`
+	Name   string  `graphql:"name"`
+	OfType ofType1 `graphql:"ofType,nofragment"`
+}
+
+type ofType1 struct {
+	Kind   string  `graphql:"kind"`
+	Name   string  `graphql:"name"`
+	OfType ofType2 `graphql:"ofType,nofragment"`
+}
+
+type ofType2 struct {
+	Kind   string  `graphql:"kind"`
+	Name   string  `graphql:"name"`
+	OfType ofType3 `graphql:"ofType,nofragment"`
+}
+
+type ofType3 struct {
+	Kind   string  `graphql:"kind"`
+	Name   string  `graphql:"name"`
+	OfType ofType4 `graphql:"ofType,nofragment"`
+}
+
+type ofType4 struct {
+	Kind   string  `graphql:"kind"`
+	Name   string  `graphql:"name"`
+	OfType ofType5 `graphql:"ofType,nofragment"`
+}
+
+type ofType5 struct {
+	Kind   string  `graphql:"kind"`
+	Name   string  `graphql:"name"`
+	OfType ofType6 `graphql:"ofType,nofragment"`
+}
+
+type ofType6 struct {
+	Kind   string  `graphql:"kind"`
+	Name   string  `graphql:"name"`
+	OfType ofType7 `graphql:"ofType,nofragment"`
+}
+
+type ofType7 struct {
+	Kind string `graphql:"kind"`
+	Name string `graphql:"name"`
+}
+
+// ParseIntrospection 加载内省查询的 JSON 结果，得到与 Parse 相同的 schema 模型；
+// 支持完整响应 {"data":{"__schema":...}} 与仅包含 {"__schema":...} 的快照
+func ParseIntrospection(data []byte) (*Schema, error) {
+	var result struct {
+		Data *struct {
+			Schema *jsonSchema `json:"__schema"`
+		} `json:"data"`
+		Schema *jsonSchema `json:"__schema"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("schema: invalid introspection result: %w", err)
+	}
+	raw := result.Schema
+	if raw == nil && result.Data != nil {
+		raw = result.Data.Schema
+	}
+	if raw == nil {
+		return nil, errors.New("schema: introspection result has no __schema")
+	}
+
+	s := &Schema{Types: make(map[string]*Type), Directives: make(map[string]*Directive)}
+	if raw.QueryType != nil {
+		s.QueryType = raw.QueryType.Name
+	}
+	if raw.MutationType != nil {
+		s.MutationType = raw.MutationType.Name
+	}
+	if raw.SubscriptionType != nil {
+		s.SubscriptionType = raw.SubscriptionType.Name
+	}
+	for _, jt := range raw.Types {
+		if _, ok := s.Types[jt.Name]; ok {
+			return nil, fmt.Errorf("schema: type %q is defined more than once", jt.Name)
+		}
+		t := &Type{Kind: jt.Kind, Name: jt.Name, Description: jt.Description, InputFields: newInputValues(jt.InputFields)}
+		for _, jf := range jt.Fields {
+			t.Fields = append(t.Fields, &Field{Name: jf.Name, Description: jf.Description, Args: newInputValues(jf.Args), Type: jf.Type.typeRef()})
+		}
+		for _, i := range jt.Interfaces {
+			t.Interfaces = append(t.Interfaces, i.Name)
+		}
+		for _, m := range jt.PossibleTypes {
+			t.PossibleTypes = append(t.PossibleTypes, m.Name)
+		}
+		for _, v := range jt.EnumValues {
+			t.EnumValues = append(t.EnumValues, v.Name)
+		}
+		s.Types[t.Name] = t
+	}
+	for _, jd := range raw.Directives {
+		s.Directives[jd.Name] = &Directive{Name: jd.Name, Args: newInputValues(jd.Args), Locations: jd.Locations, Repeatable: jd.IsRepeatable}
+	}
+	s.addBuiltins()
+	if err := s.resolve(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// ParseIntrospectionFile 读取并加载内省结果 JSON 文件
+func ParseIntrospectionFile(path string) (*Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseIntrospection(data)
+}
+
+// jsonSchema 等为内省结果的 JSON 结构，ofType 可任意嵌套
+type jsonSchema struct {
+	QueryType        *jsonTypeRef `json:"queryType"`
+	MutationType     *jsonTypeRef `json:"mutationType"`
+	SubscriptionType *jsonTypeRef `json:"subscriptionType"`
+	Types            []*jsonType  `json:"types"`
+	Directives       []struct {
+		Name         string            `json:"name"`
+		Locations    []string          `json:"locations"`
+		Args         []*jsonInputValue `json:"args"`
+		IsRepeatable bool              `json:"isRepeatable"`
+	} `json:"directives"`
+}
+
+type jsonType struct {
+	Kind        Kind   `json:"kind"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Fields      []struct {
+		Name        string            `json:"name"`
+		Description string            `json:"description"`
+		Args        []*jsonInputValue `json:"args"`
+		Type        *jsonTypeRef      `json:"type"`
+	} `json:"fields"`
+	InputFields   []*jsonInputValue `json:"inputFields"`
+	Interfaces    []*jsonTypeRef    `json:"interfaces"`
+	PossibleTypes []*jsonTypeRef    `json:"possibleTypes"`
+	EnumValues    []struct {
+		Name string `json:"name"`
+	} `json:"enumValues"`
+}
+
+type jsonInputValue struct {
+	Name         string       `json:"name"`
+	Description  string       `json:"description"`
+	Type         *jsonTypeRef `json:"type"`
+	DefaultValue *string      `json:"defaultValue"`
+}
+
+type jsonTypeRef struct {
+	Kind   Kind         `json:"kind"`
+	Name   string       `json:"name"`
+	OfType *jsonTypeRef `json:"ofType"`
+}
+
+func newInputValues(values []*jsonInputValue) []*InputValue {
+	var result []*InputValue
+	for _, v := range values {
+		result = append(result, &InputValue{Name: v.Name, Description: v.Description, Type: v.Type.typeRef(), DefaultValue: v.DefaultValue})
+	}
+	return result
+}
+
+func (r *jsonTypeRef) typeRef() *TypeRef {
+	if r == nil {
+		return nil
+	}
+	return &TypeRef{Kind: r.Kind, Name: r.Name, OfType: r.OfType.typeRef()}
+}
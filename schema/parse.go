@@ -0,0 +1,660 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/lascyb/struct-to-graphql/core"
+	"github.com/lascyb/struct-to-graphql/internal/lexer"
+)
+
+// SyntaxError SDL 语法错误，Line / Column 从 1 开始
+type SyntaxError struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("schema: syntax error at %d:%d: %s", e.Line, e.Column, e.Message)
+}
+
+// Parse 解析 SDL 文本，支持 schema / scalar / type / interface / union / enum / input / directive 定义及其 extend 扩展；
+// 未声明的内置标量（Int、Float、String、Boolean、ID）与内置指令（@include、@skip、@deprecated、@specifiedBy）会自动补全
+func Parse(sdl string) (*Schema, error) {
+	p, err := newParser(sdl)
+	if err != nil {
+		return nil, err
+	}
+	s := &Schema{Types: make(map[string]*Type), Directives: make(map[string]*Directive)}
+	hasSchemaDefinition := false
+	for p.tok.Kind != lexer.EOF {
+		isSchema, err := p.parseDefinition(s)
+		if err != nil {
+			return nil, err
+		}
+		hasSchemaDefinition = hasSchemaDefinition || isSchema
+	}
+	// 未显式声明 schema 时按约定名称确定根类型
+	if !hasSchemaDefinition {
+		for name, root := range map[string]*string{"Query": &s.QueryType, "Mutation": &s.MutationType, "Subscription": &s.SubscriptionType} {
+			if _, ok := s.Types[name]; ok {
+				*root = name
+			}
+		}
+	}
+	s.addBuiltins()
+	if err := s.resolve(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// ParseFile 读取并解析 SDL 文件
+func ParseFile(path string) (*Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(string(data))
+}
+
+// parser SDL 递归下降解析器，tok 为当前词法单元
+type parser struct {
+	lex *lexer.Lexer
+	tok lexer.Token
+}
+
+func newParser(src string) (*parser, error) {
+	p := &parser{lex: lexer.New(src)}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lex.Next()
+	if err != nil {
+		var lexErr *lexer.Error
+		if errors.As(err, &lexErr) {
+			return &SyntaxError{Line: lexErr.Line, Column: lexErr.Column, Message: lexErr.Message}
+		}
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return &SyntaxError{Line: p.tok.Line, Column: p.tok.Column, Message: fmt.Sprintf(format, args...)}
+}
+
+// isPunct 判断当前词法单元是否为指定标点
+func (p *parser) isPunct(value string) bool {
+	return p.tok.Kind == lexer.Punctuator && p.tok.Value == value
+}
+
+// isKeyword 判断当前词法单元是否为指定名称
+func (p *parser) isKeyword(value string) bool {
+	return p.tok.Kind == lexer.Name && p.tok.Value == value
+}
+
+// skipPunct 当前为指定标点时跳过并返回 true
+func (p *parser) skipPunct(value string) (bool, error) {
+	if !p.isPunct(value) {
+		return false, nil
+	}
+	return true, p.advance()
+}
+
+func (p *parser) expectPunct(value string) error {
+	if !p.isPunct(value) {
+		return p.errorf("expected %q, found %s", value, p.tok)
+	}
+	return p.advance()
+}
+
+func (p *parser) expectKeyword(value string) error {
+	if !p.isKeyword(value) {
+		return p.errorf("expected %q, found %s", value, p.tok)
+	}
+	return p.advance()
+}
+
+func (p *parser) expectName() (string, error) {
+	if p.tok.Kind != lexer.Name {
+		return "", p.errorf("expected name, found %s", p.tok)
+	}
+	name := p.tok.Value
+	return name, p.advance()
+}
+
+// parseDescription 读取可选的描述字符串
+func (p *parser) parseDescription() (string, error) {
+	if p.tok.Kind != lexer.String && p.tok.Kind != lexer.BlockString {
+		return "", nil
+	}
+	desc := p.tok.Value
+	return desc, p.advance()
+}
+
+// parseDefinition 解析一个顶层定义，返回是否为 schema 定义
+func (p *parser) parseDefinition(s *Schema) (bool, error) {
+	desc, err := p.parseDescription()
+	if err != nil {
+		return false, err
+	}
+	extend := p.isKeyword("extend")
+	if extend {
+		if err := p.advance(); err != nil {
+			return false, err
+		}
+	}
+	if p.tok.Kind != lexer.Name {
+		if p.isPunct("{") {
+			return false, p.errorf("executable definitions are not supported in a schema document")
+		}
+		return false, p.errorf("unexpected %s", p.tok)
+	}
+	keyword := p.tok.Value
+	if err := p.advance(); err != nil {
+		return false, err
+	}
+	switch keyword {
+	case "schema":
+		return true, p.parseSchemaDefinition(s)
+	case "directive":
+		if extend {
+			return false, p.errorf("directive definitions cannot be extended")
+		}
+		return false, p.parseDirectiveDefinition(s)
+	}
+	kinds := map[string]Kind{"scalar": Scalar, "type": Object, "interface": Interface, "union": Union, "enum": Enum, "input": InputObject}
+	kind, ok := kinds[keyword]
+	if !ok {
+		return false, p.errorf("unexpected %q", keyword)
+	}
+	t := &Type{Kind: kind, Description: desc}
+	if t.Name, err = p.expectName(); err != nil {
+		return false, err
+	}
+	if kind == Object || kind == Interface {
+		if t.Interfaces, err = p.parseImplements(); err != nil {
+			return false, err
+		}
+	}
+	if err := p.skipDirectives(); err != nil {
+		return false, err
+	}
+	switch kind {
+	case Object, Interface:
+		t.Fields, err = p.parseFields()
+	case Union:
+		t.PossibleTypes, err = p.parseUnionMembers()
+	case Enum:
+		t.EnumValues, err = p.parseEnumValues()
+	case InputObject:
+		t.InputFields, err = p.parseInputValues("{", "}")
+	}
+	if err != nil {
+		return false, err
+	}
+	return false, p.define(s, t, extend)
+}
+
+// define 登记类型定义，extend 时合并到已有的同名同种类类型
+func (p *parser) define(s *Schema, t *Type, extend bool) error {
+	existing, ok := s.Types[t.Name]
+	if !extend {
+		if ok {
+			return p.errorf("type %q is defined more than once", t.Name)
+		}
+		s.Types[t.Name] = t
+		return nil
+	}
+	if !ok {
+		return p.errorf("cannot extend undefined type %q", t.Name)
+	}
+	if existing.Kind != t.Kind {
+		return p.errorf("cannot extend %s %q as %s", existing.Kind, t.Name, t.Kind)
+	}
+	existing.Fields = append(existing.Fields, t.Fields...)
+	existing.Interfaces = append(existing.Interfaces, t.Interfaces...)
+	existing.PossibleTypes = append(existing.PossibleTypes, t.PossibleTypes...)
+	existing.EnumValues = append(existing.EnumValues, t.EnumValues...)
+	existing.InputFields = append(existing.InputFields, t.InputFields...)
+	return nil
+}
+
+// parseSchemaDefinition 解析 schema { query: Query ... }
+func (p *parser) parseSchemaDefinition(s *Schema) error {
+	if err := p.skipDirectives(); err != nil {
+		return err
+	}
+	if !p.isPunct("{") {
+		return nil
+	}
+	if err := p.advance(); err != nil {
+		return err
+	}
+	for !p.isPunct("}") {
+		operation, err := p.expectName()
+		if err != nil {
+			return err
+		}
+		if err := p.expectPunct(":"); err != nil {
+			return err
+		}
+		name, err := p.expectName()
+		if err != nil {
+			return err
+		}
+		switch operation {
+		case "query":
+			s.QueryType = name
+		case "mutation":
+			s.MutationType = name
+		case "subscription":
+			s.SubscriptionType = name
+		default:
+			return p.errorf("unknown operation type %q", operation)
+		}
+	}
+	return p.advance()
+}
+
+// parseDirectiveDefinition 解析 directive @name(args) repeatable on LOCATION | ...
+func (p *parser) parseDirectiveDefinition(s *Schema) error {
+	if err := p.expectPunct("@"); err != nil {
+		return err
+	}
+	d := &Directive{}
+	var err error
+	if d.Name, err = p.expectName(); err != nil {
+		return err
+	}
+	if p.isPunct("(") {
+		if d.Args, err = p.parseInputValues("(", ")"); err != nil {
+			return err
+		}
+	}
+	if p.isKeyword("repeatable") {
+		d.Repeatable = true
+		if err := p.advance(); err != nil {
+			return err
+		}
+	}
+	if err := p.expectKeyword("on"); err != nil {
+		return err
+	}
+	if _, err := p.skipPunct("|"); err != nil {
+		return err
+	}
+	for {
+		location, err := p.expectName()
+		if err != nil {
+			return err
+		}
+		d.Locations = append(d.Locations, location)
+		if ok, err := p.skipPunct("|"); err != nil || !ok {
+			if err != nil {
+				return err
+			}
+			break
+		}
+	}
+	if _, ok := s.Directives[d.Name]; ok {
+		return p.errorf("directive @%s is defined more than once", d.Name)
+	}
+	s.Directives[d.Name] = d
+	return nil
+}
+
+// parseImplements 解析可选的 implements A & B
+func (p *parser) parseImplements() ([]string, error) {
+	if !p.isKeyword("implements") {
+		return nil, nil
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if _, err := p.skipPunct("&"); err != nil {
+		return nil, err
+	}
+	var names []string
+	for {
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+		if ok, err := p.skipPunct("&"); err != nil || !ok {
+			return names, err
+		}
+	}
+}
+
+// parseFields 解析可选的字段定义块 { name(args): Type @dir }
+func (p *parser) parseFields() ([]*Field, error) {
+	if !p.isPunct("{") {
+		return nil, nil
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	var fields []*Field
+	for !p.isPunct("}") {
+		f := &Field{}
+		var err error
+		if f.Description, err = p.parseDescription(); err != nil {
+			return nil, err
+		}
+		if f.Name, err = p.expectName(); err != nil {
+			return nil, err
+		}
+		if p.isPunct("(") {
+			if f.Args, err = p.parseInputValues("(", ")"); err != nil {
+				return nil, err
+			}
+		}
+		if err := p.expectPunct(":"); err != nil {
+			return nil, err
+		}
+		if f.Type, err = p.parseTypeRef(); err != nil {
+			return nil, err
+		}
+		if err := p.skipDirectives(); err != nil {
+			return nil, err
+		}
+		fields = append(fields, f)
+	}
+	return fields, p.advance()
+}
+
+// parseInputValues 解析参数列表 (a: Int = 1) 或输入对象字段块 { a: Int }
+func (p *parser) parseInputValues(open, close string) ([]*InputValue, error) {
+	if !p.isPunct(open) {
+		return nil, nil
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	var values []*InputValue
+	for !p.isPunct(close) {
+		v := &InputValue{}
+		var err error
+		if v.Description, err = p.parseDescription(); err != nil {
+			return nil, err
+		}
+		if v.Name, err = p.expectName(); err != nil {
+			return nil, err
+		}
+		if err := p.expectPunct(":"); err != nil {
+			return nil, err
+		}
+		if v.Type, err = p.parseTypeRef(); err != nil {
+			return nil, err
+		}
+		if ok, err := p.skipPunct("="); err != nil {
+			return nil, err
+		} else if ok {
+			value, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			v.DefaultValue = &value
+		}
+		if err := p.skipDirectives(); err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, p.advance()
+}
+
+// parseUnionMembers 解析可选的 = A | B
+func (p *parser) parseUnionMembers() ([]string, error) {
+	if ok, err := p.skipPunct("="); err != nil || !ok {
+		return nil, err
+	}
+	if _, err := p.skipPunct("|"); err != nil {
+		return nil, err
+	}
+	var names []string
+	for {
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+		if ok, err := p.skipPunct("|"); err != nil || !ok {
+			return names, err
+		}
+	}
+}
+
+// parseEnumValues 解析可选的枚举值块 { A B @deprecated }
+func (p *parser) parseEnumValues() ([]string, error) {
+	if !p.isPunct("{") {
+		return nil, nil
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	var values []string
+	for !p.isPunct("}") {
+		if _, err := p.parseDescription(); err != nil {
+			return nil, err
+		}
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		if name == "true" || name == "false" || name == "null" {
+			return nil, p.errorf("enum value cannot be %q", name)
+		}
+		if err := p.skipDirectives(); err != nil {
+			return nil, err
+		}
+		values = append(values, name)
+	}
+	return values, p.advance()
+}
+
+// parseTypeRef 解析类型引用 Name、[Type]、Type!
+func (p *parser) parseTypeRef() (*TypeRef, error) {
+	var ref *TypeRef
+	if ok, err := p.skipPunct("["); err != nil {
+		return nil, err
+	} else if ok {
+		inner, err := p.parseTypeRef()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectPunct("]"); err != nil {
+			return nil, err
+		}
+		ref = &TypeRef{Kind: List, OfType: inner}
+	} else {
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		ref = &TypeRef{Name: name}
+	}
+	if ok, err := p.skipPunct("!"); err != nil {
+		return nil, err
+	} else if ok {
+		ref = &TypeRef{Kind: NonNull, OfType: ref}
+	}
+	return ref, nil
+}
+
+// skipDirectives 跳过定义上声明的指令（如 @deprecated(reason: "...")）
+func (p *parser) skipDirectives() error {
+	for p.isPunct("@") {
+		if err := p.advance(); err != nil {
+			return err
+		}
+		if _, err := p.expectName(); err != nil {
+			return err
+		}
+		if ok, err := p.skipPunct("("); err != nil {
+			return err
+		} else if !ok {
+			continue
+		}
+		for !p.isPunct(")") {
+			if _, err := p.expectName(); err != nil {
+				return err
+			}
+			if err := p.expectPunct(":"); err != nil {
+				return err
+			}
+			if _, err := p.parseValue(); err != nil {
+				return err
+			}
+		}
+		if err := p.advance(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseValue 解析一个值字面量，返回其规范化的 GraphQL 写法
+func (p *parser) parseValue() (string, error) {
+	tok := p.tok
+	switch {
+	case tok.Kind == lexer.Int || tok.Kind == lexer.Float || tok.Kind == lexer.Name:
+		return tok.Value, p.advance()
+	case tok.Kind == lexer.String || tok.Kind == lexer.BlockString:
+		return core.QuoteString(tok.Value), p.advance()
+	case p.isPunct("$"):
+		if err := p.advance(); err != nil {
+			return "", err
+		}
+		name, err := p.expectName()
+		return "$" + name, err
+	case p.isPunct("["):
+		if err := p.advance(); err != nil {
+			return "", err
+		}
+		var items []string
+		for !p.isPunct("]") {
+			item, err := p.parseValue()
+			if err != nil {
+				return "", err
+			}
+			items = append(items, item)
+		}
+		return "[" + strings.Join(items, ",") + "]", p.advance()
+	case p.isPunct("{"):
+		if err := p.advance(); err != nil {
+			return "", err
+		}
+		var fields []string
+		for !p.isPunct("}") {
+			name, err := p.expectName()
+			if err != nil {
+				return "", err
+			}
+			if err := p.expectPunct(":"); err != nil {
+				return "", err
+			}
+			value, err := p.parseValue()
+			if err != nil {
+				return "", err
+			}
+			fields = append(fields, name+":"+value)
+		}
+		return "{" + strings.Join(fields, ",") + "}", p.advance()
+	}
+	return "", p.errorf("unexpected %s, expected a value", tok)
+}
+
+// resolve 检查所有类型引用均已定义，并为命名类型引用补全 Kind
+func (s *Schema) resolve() error {
+	resolveRef := func(ref *TypeRef, where string) error {
+		if ref == nil {
+			return fmt.Errorf("schema: missing type of %s", where)
+		}
+		for r := ref; r != nil; r = r.OfType {
+			if r.OfType != nil {
+				continue
+			}
+			t, ok := s.Types[r.Name]
+			if !ok {
+				return fmt.Errorf("schema: unknown type %q referenced by %s", r.Name, where)
+			}
+			r.Kind = t.Kind
+		}
+		return nil
+	}
+	resolveInputs := func(values []*InputValue, where string) error {
+		for _, v := range values {
+			if err := resolveRef(v.Type, where+"("+v.Name+":)"); err != nil {
+				return err
+			}
+			if t := s.Types[v.Type.NamedType()]; !t.IsInput() {
+				return fmt.Errorf("schema: %s(%s:) must be an input type, got %s %q", where, v.Name, t.Kind, t.Name)
+			}
+		}
+		return nil
+	}
+	for _, name := range s.typeNames() {
+		t := s.Types[name]
+		for _, f := range t.Fields {
+			where := name + "." + f.Name
+			if err := resolveRef(f.Type, where); err != nil {
+				return err
+			}
+			if s.Types[f.Type.NamedType()].Kind == InputObject {
+				return fmt.Errorf("schema: field %s cannot be of input type %q", where, f.Type.NamedType())
+			}
+			if err := resolveInputs(f.Args, where); err != nil {
+				return err
+			}
+		}
+		for _, i := range t.Interfaces {
+			if it, ok := s.Types[i]; !ok || it.Kind != Interface {
+				return fmt.Errorf("schema: type %q implements %q which is not an interface", name, i)
+			}
+		}
+		for _, m := range t.PossibleTypes {
+			if mt, ok := s.Types[m]; !ok || mt.Kind != Object {
+				return fmt.Errorf("schema: union %q member %q is not an object type", name, m)
+			}
+		}
+		for _, f := range t.InputFields {
+			if err := resolveRef(f.Type, name+"."+f.Name); err != nil {
+				return err
+			}
+			if !s.Types[f.Type.NamedType()].IsInput() {
+				return fmt.Errorf("schema: input field %s.%s must be an input type", name, f.Name)
+			}
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(s.Directives)) {
+		if err := resolveInputs(s.Directives[name].Args, "@"+name); err != nil {
+			return err
+		}
+	}
+	for operation, root := range map[string]string{"query": s.QueryType, "mutation": s.MutationType, "subscription": s.SubscriptionType} {
+		if root == "" {
+			continue
+		}
+		if t, ok := s.Types[root]; !ok || t.Kind != Object {
+			return fmt.Errorf("schema: %s root type %q must be a defined object type", operation, root)
+		}
+	}
+	if s.QueryType == "" {
+		return errors.New("schema: query root type is not defined")
+	}
+	return nil
+}
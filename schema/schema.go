@@ -0,0 +1,301 @@
+// Package schema 加载 GraphQL schema（SDL），并校验由结构体生成的选择集是否与 schema 一致
+package schema
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/lascyb/struct-to-graphql/internal/lexer"
+)
+
+// Kind 命名类型的种类，取值与内省结果中的 __TypeKind 一致
+type Kind string
+
+const (
+	Scalar      Kind = "SCALAR"
+	Object      Kind = "OBJECT"
+	Interface   Kind = "INTERFACE"
+	Union       Kind = "UNION"
+	Enum        Kind = "ENUM"
+	InputObject Kind = "INPUT_OBJECT"
+)
+
+// Schema 已加载的 GraphQL schema
+type Schema struct {
+	QueryType        string                // 查询根类型名，未定义时为空
+	MutationType     string                // 变更根类型名，未定义时为空
+	SubscriptionType string                // 订阅根类型名，未定义时为空
+	Types            map[string]*Type      // 全部命名类型（含内置标量）
+	Directives       map[string]*Directive // 全部指令定义（含内置指令）
+}
+
+// Type 命名类型定义
+type Type struct {
+	Kind          Kind
+	Name          string
+	Description   string
+	Fields        []*Field      // OBJECT / INTERFACE 的字段
+	Interfaces    []string      // OBJECT / INTERFACE 实现的接口
+	PossibleTypes []string      // UNION 的成员类型
+	EnumValues    []string      // ENUM 的取值
+	InputFields   []*InputValue // INPUT_OBJECT 的字段
+}
+
+// Field 对象或接口类型上的字段定义
+type Field struct {
+	Name        string
+	Description string
+	Args        []*InputValue
+	Type        *TypeRef
+}
+
+// InputValue 参数或输入对象字段的定义
+type InputValue struct {
+	Name         string
+	Description  string
+	Type         *TypeRef
+	DefaultValue *string // 默认值的 GraphQL 字面量，未声明时为 nil
+}
+
+// Directive 指令定义
+type Directive struct {
+	Name       string
+	Args       []*InputValue
+	Locations  []string // 允许的位置，如 FIELD、INLINE_FRAGMENT
+	Repeatable bool
+}
+
+// TypeRef 类型引用：Kind 为 NON_NULL / LIST 时由 OfType 描述内层类型，否则为命名类型 Name
+type TypeRef struct {
+	Kind   Kind
+	Name   string
+	OfType *TypeRef
+}
+
+const (
+	NonNull Kind = "NON_NULL"
+	List    Kind = "LIST"
+)
+
+// String 返回类型引用的 GraphQL 写法，如 "[ID!]!"
+func (r *TypeRef) String() string {
+	switch r.Kind {
+	case NonNull:
+		return r.OfType.String() + "!"
+	case List:
+		return "[" + r.OfType.String() + "]"
+	}
+	return r.Name
+}
+
+// NamedType 返回去掉列表与非空修饰后的命名类型名
+func (r *TypeRef) NamedType() string {
+	for r.OfType != nil {
+		r = r.OfType
+	}
+	return r.Name
+}
+
+// IsNonNull 判断类型引用是否为非空类型
+func (r *TypeRef) IsNonNull() bool {
+	return r.Kind == NonNull
+}
+
+// ParseTypeRef 解析类型引用写法，如 "ID!"、"[String!]"
+func ParseTypeRef(s string) (*TypeRef, error) {
+	p, err := newParser(s)
+	if err != nil {
+		return nil, err
+	}
+	ref, err := p.parseTypeRef()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.Kind != lexer.EOF {
+		return nil, fmt.Errorf("invalid type reference %q", s)
+	}
+	return ref, nil
+}
+
+// Type 按名称查找命名类型，不存在时返回 nil；查找方法均可在 nil 的 *Schema 上调用，结果为未找到
+func (s *Schema) Type(name string) *Type {
+	if s == nil {
+		return nil
+	}
+	return s.Types[name]
+}
+
+// RootType 返回操作类型（"query"、"mutation"、"subscription"）对应的根类型，未定义时返回 nil
+func (s *Schema) RootType(operation string) *Type {
+	if s == nil {
+		return nil
+	}
+	switch strings.ToLower(operation) {
+	case "query":
+		return s.Types[s.QueryType]
+	case "mutation":
+		return s.Types[s.MutationType]
+	case "subscription":
+		return s.Types[s.SubscriptionType]
+	}
+	return nil
+}
+
+// PossibleTypes 返回抽象类型的全部具体对象类型：联合类型为其成员，接口类型为实现它的对象类型，对象类型为自身
+func (s *Schema) PossibleTypes(t *Type) []string {
+	switch t.Kind {
+	case Object:
+		return []string{t.Name}
+	case Union:
+		return t.PossibleTypes
+	case Interface:
+		var names []string
+		for _, name := range s.typeNames() {
+			if typ := s.Types[name]; typ.Kind == Object && typ.Implements(t.Name) {
+				names = append(names, name)
+			}
+		}
+		return names
+	}
+	return nil
+}
+
+// RootTypeName 返回操作类型对应的根类型名，未定义时返回空字符串；与 FieldTypeName、ArgumentType、
+// DirectiveArgumentType、InputFieldType 一起实现 core.Schema，可通过 graphql.Options.Schema 推断变量类型
+func (s *Schema) RootTypeName(operation string) string {
+	if t := s.RootType(operation); t != nil {
+		return t.Name
+	}
+	return ""
+}
+
+// FieldTypeName 返回 parent 类型上字段 field 的命名类型名，不存在时返回空字符串
+func (s *Schema) FieldTypeName(parent, field string) string {
+	if f := s.field(parent, field); f != nil {
+		return f.Type.NamedType()
+	}
+	return ""
+}
+
+// ArgumentType 返回 parent 类型上字段 field 的参数 arg 的类型（如 "ID!"），不存在时返回空字符串
+func (s *Schema) ArgumentType(parent, field, arg string) string {
+	if f := s.field(parent, field); f != nil {
+		if a := f.Arg(arg); a != nil {
+			return a.Type.String()
+		}
+	}
+	return ""
+}
+
+// DirectiveArgumentType 返回指令 directive 的参数 arg 的类型，不存在时返回空字符串
+func (s *Schema) DirectiveArgumentType(directive, arg string) string {
+	if s == nil {
+		return ""
+	}
+	if d, ok := s.Directives[directive]; ok {
+		if a := d.Arg(arg); a != nil {
+			return a.Type.String()
+		}
+	}
+	return ""
+}
+
+// InputFieldType 返回输入对象类型 input 的字段 field 的类型，不存在时返回空字符串
+func (s *Schema) InputFieldType(input, field string) string {
+	if t := s.Type(input); t != nil {
+		if f := t.InputField(field); f != nil {
+			return f.Type.String()
+		}
+	}
+	return ""
+}
+
+func (s *Schema) field(parent, field string) *Field {
+	if t := s.Type(parent); t != nil {
+		return t.Field(field)
+	}
+	return nil
+}
+
+// Field 按名称查找字段，不存在时返回 nil
+func (t *Type) Field(name string) *Field {
+	for _, f := range t.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// InputField 按名称查找输入对象字段，不存在时返回 nil
+func (t *Type) InputField(name string) *InputValue {
+	return findInputValue(t.InputFields, name)
+}
+
+// Implements 判断类型是否声明实现了指定接口
+func (t *Type) Implements(name string) bool {
+	return slices.Contains(t.Interfaces, name)
+}
+
+// IsLeaf 判断类型是否为叶子类型（标量或枚举），叶子类型的字段不能带选择集
+func (t *Type) IsLeaf() bool {
+	return t.Kind == Scalar || t.Kind == Enum
+}
+
+// IsInput 判断类型是否可作为输入类型（标量、枚举或输入对象）
+func (t *Type) IsInput() bool {
+	return t.IsLeaf() || t.Kind == InputObject
+}
+
+// Arg 按名称查找参数定义，不存在时返回 nil
+func (f *Field) Arg(name string) *InputValue {
+	return findInputValue(f.Args, name)
+}
+
+// Arg 按名称查找参数定义，不存在时返回 nil
+func (d *Directive) Arg(name string) *InputValue {
+	return findInputValue(d.Args, name)
+}
+
+func findInputValue(values []*InputValue, name string) *InputValue {
+	for _, v := range values {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// typeNames 返回按名称排序的类型名，保证遍历顺序稳定
+func (s *Schema) typeNames() []string {
+	return slices.Sorted(maps.Keys(s.Types))
+}
+
+// builtinScalars 规范内置的标量类型
+var builtinScalars = []string{"Int", "Float", "String", "Boolean", "ID"}
+
+// addBuiltins 补全 schema 中未显式声明的内置标量与内置指令
+func (s *Schema) addBuiltins() {
+	for _, name := range builtinScalars {
+		if _, ok := s.Types[name]; !ok {
+			s.Types[name] = &Type{Kind: Scalar, Name: name}
+		}
+	}
+	nonNull := func(name string) *TypeRef {
+		return &TypeRef{Kind: NonNull, OfType: &TypeRef{Kind: Scalar, Name: name}}
+	}
+	reason := `"No longer supported"`
+	builtins := []*Directive{
+		{Name: "include", Args: []*InputValue{{Name: "if", Type: nonNull("Boolean")}}, Locations: []string{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"}},
+		{Name: "skip", Args: []*InputValue{{Name: "if", Type: nonNull("Boolean")}}, Locations: []string{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"}},
+		{Name: "deprecated", Args: []*InputValue{{Name: "reason", Type: &TypeRef{Kind: Scalar, Name: "String"}, DefaultValue: &reason}}, Locations: []string{"FIELD_DEFINITION", "ARGUMENT_DEFINITION", "INPUT_FIELD_DEFINITION", "ENUM_VALUE"}},
+		{Name: "specifiedBy", Args: []*InputValue{{Name: "url", Type: nonNull("String")}}, Locations: []string{"SCALAR"}},
+	}
+	for _, d := range builtins {
+		if _, ok := s.Directives[d.Name]; !ok {
+			s.Directives[d.Name] = d
+		}
+	}
+}
@@ -0,0 +1,369 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	graphql "github.com/lascyb/struct-to-graphql"
+	"github.com/lascyb/struct-to-graphql/core"
+)
+
+var _ core.Schema = (*Schema)(nil)
+
+// ValidationError 单个校验问题：GoPath 为 Go 结构体字段路径（如 "ProductQuery.Product.Title"），
+// Path 为对应的 GraphQL 响应路径（如 "product.title"），便于直接定位到需要修改的 struct tag
+type ValidationError struct {
+	GoPath  string
+	Path    string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.GoPath, e.Message)
+}
+
+// ValidationErrors 一次校验发现的全部问题，按选择集遍历顺序排列
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Unwrap 支持 errors.Is / errors.As 匹配其中的单个错误
+func (e ValidationErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// Validate 校验由 graphql.Marshal 生成的文档作为 operation（"query"、"mutation"、"subscription"）执行时是否符合 schema，
+// 存在问题时返回 ValidationErrors
+func (s *Schema) Validate(g *graphql.Graphql, operation string) error {
+	if g == nil {
+		return fmt.Errorf("schema: nothing to validate")
+	}
+	return s.ValidateType(g.TypeParser(), operation)
+}
+
+// ValidateType 校验结构体解析结果（TypeParser 树）作为 operation 的选择集是否符合 schema：
+// 字段是否存在于父类型、参数是否存在且类型兼容、必填参数是否缺失、联合类型/接口类型分支是否合法、
+// 叶子字段不能有选择集而对象字段必须有选择集，以及指令是否已定义并可用于该位置
+func (s *Schema) ValidateType(typeParser *core.TypeParser, operation string) error {
+	if typeParser == nil {
+		return fmt.Errorf("schema: nothing to validate")
+	}
+	root := s.RootType(operation)
+	if root == nil {
+		return fmt.Errorf("schema: no %s root type is defined", operation)
+	}
+	rootName := "(root)"
+	if typ := typeParser.Type(); typ != nil && typ.Name() != "" {
+		rootName = typ.Name()
+	}
+	v := &validator{schema: s, isQuery: root.Name == s.QueryType}
+	v.selectionSet(typeParser, root, []string{rootName}, nil)
+	if len(v.errs) > 0 {
+		return v.errs
+	}
+	return nil
+}
+
+// validator 遍历选择集并收集全部问题
+type validator struct {
+	schema  *Schema
+	isQuery bool
+	errs    ValidationErrors
+}
+
+func (v *validator) report(goPath, path []string, format string, args ...any) {
+	v.errs = append(v.errs, &ValidationError{
+		GoPath:  strings.Join(goPath, "."),
+		Path:    strings.Join(path, "."),
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// selectionSet 校验 typeParser 的全部字段作为 parent 类型的选择集
+func (v *validator) selectionSet(typeParser *core.TypeParser, parent *Type, goPath, path []string) {
+	for _, field := range typeParser.Fields {
+		fieldGoPath := append(slices.Clip(goPath), field.StructField().Name)
+		branch := typeParser.Union || (typeParser.Interface && field.Inline)
+		switch {
+		case branch && field.FieldName != "__typename":
+			v.directives(field, []string{"INLINE_FRAGMENT"}, fieldGoPath, path)
+			v.typeCondition(field, parent, fieldGoPath, path)
+		case field.Inline:
+			// 匿名嵌入字段平铺到当前选择集（或作为无类型条件的内联片段/片段展开），父类型不变
+			v.directives(field, []string{"INLINE_FRAGMENT", "FRAGMENT_SPREAD"}, fieldGoPath, path)
+			if field.TypeParser != nil {
+				v.selectionSet(field.TypeParser, parent, fieldGoPath, path)
+			}
+		default:
+			v.field(field, parent, fieldGoPath, path)
+		}
+	}
+}
+
+// typeCondition 校验联合类型/接口类型分支 "... on Type"：类型必须存在、为复合类型，且与父类型存在交集
+func (v *validator) typeCondition(field *core.FieldParser, parent *Type, goPath, path []string) {
+	cond := v.schema.Types[field.TypeName]
+	if cond == nil {
+		v.report(goPath, path, "unknown type %q in fragment type condition", field.TypeName)
+		return
+	}
+	if cond.Kind != Object && cond.Kind != Interface && cond.Kind != Union {
+		v.report(goPath, path, "fragment cannot condition on non-composite type %q", cond.Name)
+		return
+	}
+	possible := v.schema.PossibleTypes(parent)
+	if !slices.ContainsFunc(v.schema.PossibleTypes(cond), func(name string) bool { return slices.Contains(possible, name) }) {
+		v.report(goPath, path, "type %q can never be of type %q", parent.Name, cond.Name)
+		return
+	}
+	if field.TypeParser != nil {
+		v.selectionSet(field.TypeParser, cond, goPath, path)
+	}
+}
+
+// field 校验普通字段：字段定义、参数、指令与选择集
+func (v *validator) field(field *core.FieldParser, parent *Type, goPath, path []string) {
+	name := field.GraphQLName()
+	fieldPath := append(slices.Clip(path), field.ResponseKey())
+	v.directives(field, []string{"FIELD"}, goPath, fieldPath)
+	if name == "__typename" {
+		if field.TypeParser != nil {
+			v.report(goPath, fieldPath, "field \"__typename\" of type \"String!\" must not have a selection")
+		}
+		return
+	}
+	// 内省字段（__schema、__type）不在用户 schema 中定义，跳过校验
+	if v.isQuery && (name == "__schema" || name == "__type") {
+		return
+	}
+	def := parent.Field(name)
+	if def == nil {
+		v.report(goPath, fieldPath, "field %q is not defined on type %q", name, parent.Name)
+		return
+	}
+	var args map[string]*core.Arg
+	var argNames []string
+	if field.TagValue != nil {
+		args, argNames = field.TagValue.Args, field.TagValue.ArgNames
+	}
+	v.arguments(args, argNames, def.Args, fmt.Sprintf("field %q", name), goPath, fieldPath)
+
+	typ := v.schema.Types[def.Type.NamedType()]
+	if typ == nil {
+		v.report(goPath, fieldPath, "unknown type %q of field %q", def.Type.NamedType(), name)
+		return
+	}
+	switch {
+	case typ.IsLeaf() && field.TypeParser != nil:
+		v.report(goPath, fieldPath, "field %q of type %q must not have a selection", name, def.Type)
+	case !typ.IsLeaf() && field.TypeParser == nil:
+		v.report(goPath, fieldPath, "field %q of type %q must have a selection of subfields", name, def.Type)
+	case !typ.IsLeaf():
+		v.selectionSet(field.TypeParser, typ, goPath, fieldPath)
+	}
+}
+
+// directives 校验字段上的指令：指令必须已定义并允许出现在 locations 中的任一位置
+func (v *validator) directives(field *core.FieldParser, locations []string, goPath, path []string) {
+	if field.TagValue == nil {
+		return
+	}
+	for _, d := range field.TagValue.Directives {
+		def := v.schema.Directives[d.Name]
+		if def == nil {
+			v.report(goPath, path, "unknown directive \"@%s\"", d.Name)
+			continue
+		}
+		if !slices.ContainsFunc(locations, func(l string) bool { return slices.Contains(def.Locations, l) }) {
+			v.report(goPath, path, "directive \"@%s\" may not be used on %s", d.Name, locations[0])
+			continue
+		}
+		v.arguments(d.Args, d.ArgNames, def.Args, fmt.Sprintf("directive \"@%s\"", d.Name), goPath, path)
+	}
+}
+
+// arguments 校验参数：参数必须已定义、值与类型兼容，且非空无默认值的参数必须提供
+func (v *validator) arguments(args map[string]*core.Arg, names []string, defs []*InputValue, where string, goPath, path []string) {
+	for _, name := range names {
+		def := findInputValue(defs, name)
+		if def == nil {
+			v.report(goPath, path, "unknown argument %q on %s", name, where)
+			continue
+		}
+		if problem := v.argument(args[name], def.Type); problem != "" {
+			v.report(goPath, path, "argument %q on %s: %s", name, where, problem)
+		}
+	}
+	for _, def := range defs {
+		if def.Type.IsNonNull() && def.DefaultValue == nil {
+			if _, ok := args[def.Name]; !ok {
+				v.report(goPath, path, "missing required argument %q of type %q on %s", def.Name, def.Type, where)
+			}
+		}
+	}
+}
+
+// argument 校验单个参数值，返回问题描述，兼容时返回空字符串
+func (v *validator) argument(arg *core.Arg, location *TypeRef) string {
+	if arg.ArgValue.Type != "variable" {
+		return v.literal(arg.Value, location)
+	}
+	return v.variable(arg.GraphQLType, arg.HasDefault && arg.DefaultVal != nil, location)
+}
+
+// variable 校验变量类型能否用于参数位置，hasDefault 表示变量带非 null 默认值
+func (v *validator) variable(typ string, hasDefault bool, location *TypeRef) string {
+	if typ == "" {
+		// 未声明类型的变量无法在此校验，Query/Mutation 生成时会报告缺少类型定义
+		return ""
+	}
+	varType, err := ParseTypeRef(typ)
+	if err != nil {
+		return fmt.Sprintf("invalid variable type %q", typ)
+	}
+	named := v.schema.Types[varType.NamedType()]
+	if named == nil {
+		return fmt.Sprintf("variable type %q is not defined", varType.NamedType())
+	}
+	if !named.IsInput() {
+		return fmt.Sprintf("variable type %q is not an input type", varType)
+	}
+	// 可空变量带非 null 默认值时可用于非空位置
+	if location.IsNonNull() && !varType.IsNonNull() && hasDefault {
+		location = location.OfType
+	}
+	if !isSubtype(varType, location) {
+		return fmt.Sprintf("variable of type %q is not compatible with %q", varType, location)
+	}
+	return ""
+}
+
+// isSubtype 判断变量类型是否可用于参数位置：非空变量可用于可空位置，其余须逐层一致
+func isSubtype(varType, location *TypeRef) bool {
+	if location.Kind == NonNull {
+		return varType.Kind == NonNull && isSubtype(varType.OfType, location.OfType)
+	}
+	if varType.Kind == NonNull {
+		return isSubtype(varType.OfType, location)
+	}
+	if location.Kind == List {
+		return varType.Kind == List && isSubtype(varType.OfType, location.OfType)
+	}
+	return varType.Kind != List && varType.Name == location.Name
+}
+
+// literal 校验字面量参数值与参数类型是否兼容：单个值可按输入强制转换规则用于列表位置，
+// 复合字面量逐个校验其中的字段、元素与变量占位符
+func (v *validator) literal(value any, location *TypeRef) string {
+	if ref, ok := value.(*core.VariableRef); ok {
+		return v.variable(ref.Type, ref.HasDefault && ref.DefaultVal != nil, location)
+	}
+	if value == nil {
+		if location.IsNonNull() {
+			return fmt.Sprintf("null is not allowed for %q", location)
+		}
+		return ""
+	}
+	if list, ok := value.(core.ListValue); ok {
+		item := location
+		if item.Kind == NonNull {
+			item = item.OfType
+		}
+		if item.Kind != List {
+			return fmt.Sprintf("value %s is not a valid %q", formatLiteral(value), location)
+		}
+		for _, value := range list {
+			if problem := v.literal(value, item.OfType); problem != "" {
+				return problem
+			}
+		}
+		return ""
+	}
+	named := location
+	for named.Kind == NonNull || named.Kind == List {
+		named = named.OfType
+	}
+	typ := v.schema.Types[named.Name]
+	if typ == nil {
+		return fmt.Sprintf("unknown type %q", named.Name)
+	}
+	if object, ok := value.(core.ObjectValue); ok {
+		if typ.Kind != InputObject {
+			return fmt.Sprintf("value %s is not a valid %q", formatLiteral(value), location)
+		}
+		return v.object(object, typ)
+	}
+	if enum, ok := value.(core.Enum); ok {
+		if typ.Kind != Enum {
+			return fmt.Sprintf("enum value %s is not a valid %q", enum.GraphQLEnum(), location)
+		}
+		if !slices.Contains(typ.EnumValues, enum.GraphQLEnum()) {
+			return fmt.Sprintf("enum %q has no value %s", typ.Name, enum.GraphQLEnum())
+		}
+		return ""
+	}
+	rv := reflect.ValueOf(value)
+	isInt := rv.CanInt() || rv.CanUint() || (rv.CanFloat() && rv.Float() == float64(int64(rv.Float())))
+	ok := true
+	switch {
+	case typ.Kind == Enum:
+		// 字符串字面量总是输出为带引号的字符串，枚举值需写作 enum(NAME)
+		return fmt.Sprintf("enum %q expects an enum value written as enum(NAME), got %s", typ.Name, formatLiteral(value))
+	case typ.Kind == InputObject:
+		ok = false
+	case typ.Name == "Int":
+		ok = isInt
+	case typ.Name == "Float":
+		ok = isInt || rv.CanFloat()
+	case typ.Name == "String":
+		ok = rv.Kind() == reflect.String
+	case typ.Name == "Boolean":
+		ok = rv.Kind() == reflect.Bool
+	case typ.Name == "ID":
+		ok = rv.Kind() == reflect.String || isInt
+	}
+	if !ok {
+		return fmt.Sprintf("value %s is not a valid %q", formatLiteral(value), location)
+	}
+	return ""
+}
+
+// object 校验输入对象字面量：字段必须已定义、值与字段类型兼容，且非空无默认值的字段必须提供
+func (v *validator) object(object core.ObjectValue, typ *Type) string {
+	provided := make(map[string]bool, len(object))
+	for _, field := range object {
+		provided[field.Name] = true
+		def := typ.InputField(field.Name)
+		if def == nil {
+			return fmt.Sprintf("unknown field %q on input type %q", field.Name, typ.Name)
+		}
+		if problem := v.literal(field.Value, def.Type); problem != "" {
+			return fmt.Sprintf("field %q of %q: %s", field.Name, typ.Name, problem)
+		}
+	}
+	for _, def := range typ.InputFields {
+		if def.Type.IsNonNull() && def.DefaultValue == nil && !provided[def.Name] {
+			return fmt.Sprintf("missing required field %q of type %q on input type %q", def.Name, def.Type, typ.Name)
+		}
+	}
+	return ""
+}
+
+func formatLiteral(value any) string {
+	if s, ok := value.(string); ok {
+		return core.QuoteString(s)
+	}
+	return fmt.Sprint(value)
+}
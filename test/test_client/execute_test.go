@@ -0,0 +1,264 @@
+package test_client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lascyb/struct-to-graphql/client"
+	"github.com/lascyb/struct-to-graphql/schema"
+)
+
+// 测试 HTTP 执行器
+type ProductQuery struct {
+	Product struct {
+		ID    string `json:"id" graphql:"id"`
+		Title string `json:"title" graphql:"title"`
+	} `json:"product" graphql:"product(id:$id:ID!)"`
+}
+
+type ProductUpdate struct {
+	ProductUpdate struct {
+		Product struct {
+			ID string `json:"id" graphql:"id"`
+		} `json:"product" graphql:"product"`
+	} `json:"productUpdate" graphql:"productUpdate(input:$input:ProductInput!)"`
+}
+
+// newHTTPServer 启动进程内 GraphQL HTTP 服务端，校验请求体后返回固定响应
+func newHTTPServer(t *testing.T, status int, response string, check func(req subscribeRequest)) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		body, _ := io.ReadAll(r.Body)
+		var req subscribeRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Errorf("invalid request body %s: %v", body, err)
+		}
+		if check != nil {
+			check(req)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, response)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestExecuteDecodesData(t *testing.T) {
+	server := newHTTPServer(t, http.StatusOK, `{"data":{"product":{"id":"gid://1","title":"Shirt"}}}`, func(req subscribeRequest) {
+		if !strings.HasPrefix(req.Query, "query ProductQuery($id:ID!) {") {
+			t.Errorf("unexpected query:\n%s", req.Query)
+		}
+		if req.OperationName != "ProductQuery" || req.Variables["id"] != "gid://1" {
+			t.Errorf("unexpected request: %+v", req)
+		}
+	})
+
+	got, err := client.Execute[ProductQuery](context.Background(), client.New(server.URL), map[string]any{"id": "gid://1"})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if got.Product.ID != "gid://1" || got.Product.Title != "Shirt" {
+		t.Errorf("unexpected result: %+v", got)
+	}
+}
+
+func TestMutateSendsMutation(t *testing.T) {
+	server := newHTTPServer(t, http.StatusOK, `{"data":{"productUpdate":{"product":{"id":"1"}}}}`, func(req subscribeRequest) {
+		if !strings.HasPrefix(req.Query, "mutation ProductUpdate($input:ProductInput!) {") {
+			t.Errorf("unexpected mutation:\n%s", req.Query)
+		}
+	})
+
+	got, err := client.Mutate[*ProductUpdate](context.Background(), client.New(server.URL), map[string]any{"input": map[string]any{"id": "1"}})
+	if err != nil {
+		t.Fatalf("Mutate failed: %v", err)
+	}
+	if got == nil || got.ProductUpdate.Product.ID != "1" {
+		t.Errorf("unexpected result: %+v", got)
+	}
+}
+
+func TestExecuteGraphQLErrors(t *testing.T) {
+	response := `{
+		"data": {"product": {"id": "1", "title": null}},
+		"errors": [{
+			"message": "Access denied for title",
+			"locations": [{"line": 3, "column": 5}],
+			"path": ["product", "title"],
+			"extensions": {"code": "ACCESS_DENIED", "cost": 2}
+		}]
+	}`
+	server := newHTTPServer(t, http.StatusOK, response, nil)
+
+	got, err := client.Execute[ProductQuery](context.Background(), client.New(server.URL), map[string]any{"id": "1"})
+	if err == nil {
+		t.Fatal("expected GraphQL error, got nil")
+	}
+	// 部分数据仍然被解码
+	if got.Product.ID != "1" {
+		t.Errorf("partial data not decoded: %+v", got)
+	}
+	var errs client.Errors
+	if !errors.As(err, &errs) || len(errs) != 1 {
+		t.Fatalf("expected client.Errors, got %T %v", err, err)
+	}
+	var gqlErr *client.Error
+	if !errors.As(err, &gqlErr) {
+		t.Fatalf("expected *client.Error, got %v", err)
+	}
+	if gqlErr.Code() != "ACCESS_DENIED" || gqlErr.Extensions["cost"] != float64(2) {
+		t.Errorf("unexpected extensions: %+v", gqlErr.Extensions)
+	}
+	if len(gqlErr.Locations) != 1 || gqlErr.Locations[0].Line != 3 {
+		t.Errorf("unexpected locations: %+v", gqlErr.Locations)
+	}
+	if !strings.Contains(err.Error(), "product.title") {
+		t.Errorf("error should mention the path: %v", err)
+	}
+}
+
+func TestExecuteResultExtensions(t *testing.T) {
+	response := `{
+		"data": {"product": {"id": "1", "title": null}},
+		"errors": [{"message": "Access denied for title", "path": ["product", "title"]}],
+		"extensions": {"cost": {"requestedQueryCost": 3}}
+	}`
+	server := newHTTPServer(t, http.StatusOK, response, nil)
+
+	result, err := client.ExecuteResult[ProductQuery](context.Background(), client.New(server.URL), map[string]any{"id": "1"})
+	var errs client.Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected client.Errors, got %v", err)
+	}
+	if result.Data.Product.ID != "1" {
+		t.Errorf("partial data not decoded: %+v", result.Data)
+	}
+	cost, _ := result.Extensions["cost"].(map[string]any)
+	if cost["requestedQueryCost"] != float64(3) {
+		t.Errorf("unexpected extensions: %+v", result.Extensions)
+	}
+}
+
+func TestExecuteKeepsErrorsWhenDecodeFails(t *testing.T) {
+	// 部分失败：字段无法解码为 T 时，响应中的 errors 不应被丢弃
+	response := `{
+		"data": {"product": {"id": 1, "title": null}},
+		"errors": [{"message": "Cannot return null for non-nullable field Product.title", "path": ["product", "title"]}]
+	}`
+	server := newHTTPServer(t, http.StatusOK, response, nil)
+
+	_, err := client.Execute[ProductQuery](context.Background(), client.New(server.URL), map[string]any{"id": "1"})
+	var errs client.Errors
+	if !errors.As(err, &errs) || len(errs) != 1 {
+		t.Fatalf("expected client.Errors, got %T %v", err, err)
+	}
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) {
+		t.Errorf("expected the decode error to be kept, got %v", err)
+	}
+}
+
+func TestExecuteHTTPError(t *testing.T) {
+	server := newHTTPServer(t, http.StatusBadGateway, `upstream unavailable`, nil)
+
+	_, err := client.Execute[ProductQuery](context.Background(), client.New(server.URL), nil)
+	var httpErr *client.HTTPError
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("expected *client.HTTPError, got %v", err)
+	}
+	if httpErr.StatusCode != http.StatusBadGateway || string(httpErr.Body) != "upstream unavailable" {
+		t.Errorf("unexpected HTTP error: %+v", httpErr)
+	}
+}
+
+func TestExecuteErrorStatusWithGraphQLBody(t *testing.T) {
+	server := newHTTPServer(t, http.StatusBadRequest, `{"errors":[{"message":"Variable \"$id\" of required type \"ID!\" was not provided."}]}`, nil)
+
+	_, err := client.Execute[ProductQuery](context.Background(), client.New(server.URL), nil)
+	var gqlErr *client.Error
+	if !errors.As(err, &gqlErr) {
+		t.Fatalf("expected *client.Error, got %v", err)
+	}
+	if !strings.Contains(gqlErr.Message, "was not provided") {
+		t.Errorf("unexpected message: %s", gqlErr.Message)
+	}
+}
+
+func TestExecuteSendsHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Access-Token") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = io.WriteString(w, `{"data":{"product":{"id":"1"}}}`)
+	}))
+	defer server.Close()
+
+	c := client.New(server.URL)
+	c.Header = http.Header{"X-Access-Token": {"secret"}}
+	got, err := client.Execute[ProductQuery](context.Background(), c, map[string]any{"id": "1"})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if got.Product.ID != "1" {
+		t.Errorf("unexpected result: %+v", got)
+	}
+}
+
+// 别名字段按响应键解码，json tag 无需重复别名
+type AliasedProductQuery struct {
+	Product struct {
+		Name string `json:"title" graphql:"title,alias=name"`
+	} `json:"product" graphql:"product(id:$id:ID!),alias=item"`
+}
+
+func TestExecuteDecodesAliases(t *testing.T) {
+	server := newHTTPServer(t, http.StatusOK, `{"data":{"item":{"name":"Shirt"}}}`, nil)
+
+	got, err := client.Execute[AliasedProductQuery](context.Background(), client.New(server.URL), map[string]any{"id": "1"})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if got.Product.Name != "Shirt" {
+		t.Errorf("aliased field not decoded: %+v", got)
+	}
+}
+
+// 设置 Client.Schema 后，未声明类型的变量按 schema 推断
+type UntypedProductQuery struct {
+	Product struct {
+		ID string `json:"id" graphql:"id"`
+	} `json:"product" graphql:"product(id:$id)"`
+}
+
+func TestExecuteInfersVariableTypes(t *testing.T) {
+	s, err := schema.Parse(`type Query { product(id: ID!): Product } type Product { id: ID! }`)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	server := newHTTPServer(t, http.StatusOK, `{"data":{"product":{"id":"1"}}}`, func(req subscribeRequest) {
+		if !strings.HasPrefix(req.Query, "query UntypedProductQuery($id:ID!) {") {
+			t.Errorf("unexpected query:\n%s", req.Query)
+		}
+	})
+
+	c := client.New(server.URL)
+	c.Schema = s
+	got, err := client.Execute[UntypedProductQuery](context.Background(), c, map[string]any{"id": "1"})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if got.Product.ID != "1" {
+		t.Errorf("unexpected result: %+v", got)
+	}
+}
@@ -0,0 +1,249 @@
+package test_client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	graphql "github.com/lascyb/struct-to-graphql"
+	"github.com/lascyb/struct-to-graphql/client"
+	"github.com/lascyb/struct-to-graphql/internal/websocket"
+)
+
+// 测试订阅：graphql-transport-ws 协议
+type CommentAdded struct {
+	CommentAdded struct {
+		ID   string `json:"id" graphql:"id"`
+		Body string `json:"body" graphql:"body"`
+	} `json:"commentAdded" graphql:"commentAdded(postId:$postId:ID!)"`
+}
+
+type wsMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// subscribeRequest 服务端收到的 subscribe 消息
+type subscribeRequest struct {
+	Query         string         `json:"query"`
+	Variables     map[string]any `json:"variables"`
+	OperationName string         `json:"operationName"`
+}
+
+// newSubscriptionServer 启动进程内 graphql-transport-ws 服务端，完成握手后将收到的 subscribe 交给 handle
+func newSubscriptionServer(t *testing.T, handle func(conn *websocket.Conn, id string, req subscribeRequest)) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, client.Subprotocol)
+		if err != nil {
+			t.Errorf("accept failed: %v", err)
+			return
+		}
+		defer conn.Close()
+		if conn.Subprotocol != client.Subprotocol {
+			t.Errorf("got subprotocol %q, want %q", conn.Subprotocol, client.Subprotocol)
+			return
+		}
+		if msg := readServerMessage(t, conn); msg == nil || msg.Type != "connection_init" {
+			t.Errorf("expected connection_init, got %+v", msg)
+			return
+		}
+		// 握手阶段先发送 ping，客户端应回复 pong
+		writeServerMessage(t, conn, wsMessage{Type: "ping"})
+		if msg := readServerMessage(t, conn); msg == nil || msg.Type != "pong" {
+			t.Errorf("expected pong, got %+v", msg)
+			return
+		}
+		writeServerMessage(t, conn, wsMessage{Type: "connection_ack"})
+		msg := readServerMessage(t, conn)
+		if msg == nil || msg.Type != "subscribe" {
+			t.Errorf("expected subscribe, got %+v", msg)
+			return
+		}
+		var req subscribeRequest
+		if err := json.Unmarshal(msg.Payload, &req); err != nil {
+			t.Errorf("invalid subscribe payload: %v", err)
+			return
+		}
+		handle(conn, msg.ID, req)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func readServerMessage(t *testing.T, conn *websocket.Conn) *wsMessage {
+	t.Helper()
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		return nil
+	}
+	msg := new(wsMessage)
+	if err := json.Unmarshal(data, msg); err != nil {
+		t.Errorf("invalid message %s: %v", data, err)
+		return nil
+	}
+	return msg
+}
+
+func writeServerMessage(t *testing.T, conn *websocket.Conn, msg wsMessage) {
+	t.Helper()
+	data, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatalf("marshal message: %v", err)
+	}
+	if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
+		t.Errorf("write message: %v", err)
+	}
+}
+
+func TestSubscriptionOperation(t *testing.T) {
+	exec, err := graphql.Marshal(CommentAdded{})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	subscription, err := exec.Subscription("OnCommentAdded")
+	if err != nil {
+		t.Fatalf("Subscription failed: %v", err)
+	}
+	if !strings.HasPrefix(subscription, "subscription OnCommentAdded($postId:ID!) {") {
+		t.Errorf("unexpected subscription:\n%s", subscription)
+	}
+}
+
+func TestSubscribeStreamsDecodedEvents(t *testing.T) {
+	server := newSubscriptionServer(t, func(conn *websocket.Conn, id string, req subscribeRequest) {
+		if !strings.HasPrefix(req.Query, "subscription CommentAdded(") {
+			t.Errorf("unexpected query:\n%s", req.Query)
+		}
+		if req.OperationName != "CommentAdded" || req.Variables["postId"] != "p1" {
+			t.Errorf("unexpected request: %+v", req)
+		}
+		for _, body := range []string{"first", "second", "third"} {
+			payload := `{"data":{"commentAdded":{"id":"` + body + `-id","body":"` + body + `"}}}`
+			writeServerMessage(t, conn, wsMessage{ID: id, Type: "next", Payload: json.RawMessage(payload)})
+			// 其他订阅 id 的消息应被丢弃
+			writeServerMessage(t, conn, wsMessage{ID: id + "-other", Type: "next", Payload: json.RawMessage(`{"data":{"commentAdded":{"id":"other","body":"other"}}}`)})
+		}
+		writeServerMessage(t, conn, wsMessage{ID: id + "-other", Type: "error", Payload: json.RawMessage(`[{"message":"other"}]`)})
+		writeServerMessage(t, conn, wsMessage{ID: id + "-other", Type: "complete"})
+		writeServerMessage(t, conn, wsMessage{ID: id, Type: "complete"})
+		readServerMessage(t, conn)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c := client.New(server.URL)
+	var bodies []string
+	for event, err := range client.Subscribe[CommentAdded](ctx, c, map[string]any{"postId": "p1"}) {
+		if err != nil {
+			t.Fatalf("subscription error: %v", err)
+		}
+		bodies = append(bodies, event.CommentAdded.Body)
+		if event.CommentAdded.ID != event.CommentAdded.Body+"-id" {
+			t.Errorf("unexpected event: %+v", event)
+		}
+	}
+	if got := strings.Join(bodies, ","); got != "first,second,third" {
+		t.Errorf("got events %s, want first,second,third", got)
+	}
+}
+
+func TestSubscribeErrorMessage(t *testing.T) {
+	server := newSubscriptionServer(t, func(conn *websocket.Conn, id string, req subscribeRequest) {
+		payload := `[{"message":"post not found","path":["commentAdded"],"extensions":{"code":"NOT_FOUND"}}]`
+		writeServerMessage(t, conn, wsMessage{ID: id, Type: "error", Payload: json.RawMessage(payload)})
+		readServerMessage(t, conn)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var got error
+	for _, err := range client.Subscribe[CommentAdded](ctx, client.New(server.URL), nil) {
+		got = err
+	}
+	var gqlErr *client.Error
+	if !errors.As(got, &gqlErr) {
+		t.Fatalf("expected *client.Error, got %v", got)
+	}
+	if gqlErr.Message != "post not found" || gqlErr.Extensions["code"] != "NOT_FOUND" {
+		t.Errorf("unexpected error: %+v", gqlErr)
+	}
+}
+
+func TestSubscribeKeepsErrorsWhenDecodeFails(t *testing.T) {
+	// 部分失败：事件中的字段无法解码为 T 时，事件携带的 errors 不应被丢弃
+	server := newSubscriptionServer(t, func(conn *websocket.Conn, id string, req subscribeRequest) {
+		payload := `{"data":{"commentAdded":{"id":1,"body":null}},"errors":[{"message":"Cannot return null for non-nullable field Comment.body"}]}`
+		writeServerMessage(t, conn, wsMessage{ID: id, Type: "next", Payload: json.RawMessage(payload)})
+		readServerMessage(t, conn)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var got error
+	for _, err := range client.Subscribe[CommentAdded](ctx, client.New(server.URL), nil) {
+		got = err
+		break
+	}
+	var errs client.Errors
+	if !errors.As(got, &errs) || len(errs) != 1 {
+		t.Fatalf("expected client.Errors, got %T %v", got, got)
+	}
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(got, &typeErr) {
+		t.Errorf("expected the decode error to be kept, got %v", got)
+	}
+}
+
+func TestSubscribeStopEarlySendsComplete(t *testing.T) {
+	completed := make(chan string, 1)
+	server := newSubscriptionServer(t, func(conn *websocket.Conn, id string, req subscribeRequest) {
+		writeServerMessage(t, conn, wsMessage{ID: id, Type: "next", Payload: json.RawMessage(`{"data":{"commentAdded":{"id":"1","body":"hi"}}}`)})
+		if msg := readServerMessage(t, conn); msg != nil {
+			completed <- msg.Type + ":" + msg.ID
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for event, err := range client.Subscribe[CommentAdded](ctx, client.New(server.URL), nil) {
+		if err != nil {
+			t.Fatalf("subscription error: %v", err)
+		}
+		if event.CommentAdded.Body != "hi" {
+			t.Errorf("unexpected event: %+v", event)
+		}
+		break
+	}
+	select {
+	case got := <-completed:
+		if !strings.HasPrefix(got, "complete:") {
+			t.Errorf("expected complete message, got %s", got)
+		}
+	case <-ctx.Done():
+		t.Fatal("server did not receive complete")
+	}
+}
+
+func TestSubscribeContextCancel(t *testing.T) {
+	server := newSubscriptionServer(t, func(conn *websocket.Conn, id string, req subscribeRequest) {
+		// 不发送任何事件，直到客户端断开
+		readServerMessage(t, conn)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	var got error
+	for _, err := range client.Subscribe[CommentAdded](ctx, client.New(server.URL), nil) {
+		got = err
+	}
+	if !errors.Is(got, context.DeadlineExceeded) {
+		t.Fatalf("expected context deadline error, got %v", got)
+	}
+}
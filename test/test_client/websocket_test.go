@@ -0,0 +1,145 @@
+package test_client
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lascyb/struct-to-graphql/internal/websocket"
+)
+
+// 测试 WebSocket 握手的取消与不合规帧的拒绝
+func TestDialContextCancelWithoutDeadline(t *testing.T) {
+	// 接受 TCP 连接但从不响应握手
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	done := make(chan error, 1)
+	go func() {
+		_, err := websocket.Dial(ctx, "ws://"+listener.Addr().String(), nil)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Dial did not return after the context was cancelled")
+	}
+}
+
+// newRawFrameServer 完成握手后原样发送 frame，用于构造不合规的服务端帧
+func newRawFrameServer(t *testing.T, frame []byte) *httptest.Server {
+	t.Helper()
+	server, _ := newRawFrameServerWithReply(t, frame)
+	return server
+}
+
+// newRawFrameServerWithReply 与 newRawFrameServer 相同，并把客户端回应的第一帧（去掩码后的 opcode 与负载）发送到返回的通道
+func newRawFrameServerWithReply(t *testing.T, frame []byte) (*httptest.Server, <-chan []byte) {
+	t.Helper()
+	replies := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nc, brw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("hijack: %v", err)
+			return
+		}
+		defer nc.Close()
+		sum := sha1.Sum([]byte(r.Header.Get("Sec-WebSocket-Key") + "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"))
+		_, _ = brw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+			"Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(sum[:]) + "\r\n\r\n")
+		_, _ = brw.Write(frame)
+		_ = brw.Flush()
+		// 客户端的帧均带掩码，控制帧负载不超过 125 字节
+		header := make([]byte, 6)
+		if _, err := io.ReadFull(brw, header); err == nil {
+			reply := make([]byte, 1+header[1]&0x7F)
+			reply[0] = header[0] & 0x0F
+			if _, err := io.ReadFull(brw, reply[1:]); err == nil {
+				for i := range reply[1:] {
+					reply[1+i] ^= header[2+i%4]
+				}
+				replies <- reply
+			}
+		}
+		// 等待客户端关闭连接
+		_, _ = brw.ReadString(0)
+	}))
+	t.Cleanup(server.Close)
+	return server, replies
+}
+
+func TestReadMessageRejectsInvalidFrames(t *testing.T) {
+	for name, tt := range map[string]struct {
+		frame []byte
+		want  string
+	}{
+		"reserved bits":     {[]byte{0xC1, 0x00}, "reserved bits set"},
+		"masked frame":      {[]byte{0x81, 0x80, 0, 0, 0, 0}, "masked frame from server"},
+		"fragmented ping":   {[]byte{0x09, 0x00}, "fragmented control frame"},
+		"oversized ping":    {append([]byte{0x89, 126, 0x00, 126}, make([]byte, 126)...), "control frame too large"},
+		"continuation only": {[]byte{0x80, 0x00}, "unexpected continuation frame"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			server := newRawFrameServer(t, tt.frame)
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			conn, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(server.URL, "http"), nil)
+			if err != nil {
+				t.Fatalf("Dial failed: %v", err)
+			}
+			defer conn.Close()
+			if _, _, err := conn.ReadMessage(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected %q error, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestReadMessageEmptyCloseFrame(t *testing.T) {
+	// 不带状态码的关闭帧：返回 1005，但回应的关闭帧不能携带 1005
+	server, replies := newRawFrameServerWithReply(t, []byte{0x88, 0x00})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+	var closeErr *websocket.CloseError
+	if _, _, err := conn.ReadMessage(); !errors.As(err, &closeErr) || closeErr.Code != websocket.CloseNoStatusReceived {
+		t.Fatalf("expected CloseError 1005, got %v", err)
+	}
+	select {
+	case reply := <-replies:
+		if reply[0] != websocket.CloseMessage || len(reply) != 1 {
+			t.Errorf("expected an empty close frame, got opcode %d payload %v", reply[0], reply[1:])
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no close frame received")
+	}
+}
@@ -0,0 +1,112 @@
+package test_decode
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	graphql "github.com/lascyb/struct-to-graphql"
+)
+
+// 测试按响应键（别名）解码，json tag 无需重复别名
+type AliasAuthor struct {
+	ID   string `json:"id" graphql:"id"`
+	Name string `json:"name" graphql:"name,alias=displayName"`
+}
+
+type AliasMeta struct {
+	Views int `json:"views" graphql:"views,alias=viewCount"`
+}
+
+type AliasQuery struct {
+	ID       string        `json:"id" graphql:"id"`
+	Title    string        `json:"title" graphql:"title,alias=headline"`
+	Author   AliasAuthor   `json:"author" graphql:"author"`
+	Editor   *AliasAuthor  `json:"editor" graphql:"author,alias=editor"`
+	Comments []AliasAuthor `json:"comments" graphql:"comments(first:10),alias=latest"`
+	Created  time.Time     `json:"createdAt" graphql:"createdAt"`
+	Untagged string
+	AliasMeta
+}
+
+const aliasResponse = `{
+	"id": "1",
+	"headline": "My Title",
+	"author": {"id": "10", "displayName": "Alice"},
+	"editor": {"id": "11", "displayName": "Bob"},
+	"latest": [{"id": "20", "displayName": "Carol"}, {"id": "21", "displayName": "Dave"}],
+	"createdAt": "2024-05-01T10:00:00Z",
+	"Untagged": "plain",
+	"viewCount": 42
+}`
+
+func TestUnmarshalAlias(t *testing.T) {
+	var got AliasQuery
+	if err := graphql.Unmarshal([]byte(aliasResponse), &got); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if got.ID != "1" || got.Title != "My Title" {
+		t.Errorf("got ID=%q Title=%q", got.ID, got.Title)
+	}
+	if got.Author.Name != "Alice" || got.Editor == nil || got.Editor.Name != "Bob" {
+		t.Errorf("nested alias not decoded: author=%+v editor=%+v", got.Author, got.Editor)
+	}
+	if len(got.Comments) != 2 || got.Comments[1].Name != "Dave" {
+		t.Errorf("slice alias not decoded: %+v", got.Comments)
+	}
+	if !got.Created.Equal(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("json.Unmarshaler field not decoded: %v", got.Created)
+	}
+	if got.Untagged != "plain" {
+		t.Errorf("got Untagged=%q", got.Untagged)
+	}
+	if got.Views != 42 {
+		t.Errorf("embedded alias not decoded: %d", got.Views)
+	}
+}
+
+func TestGraphqlUnmarshalReusesParser(t *testing.T) {
+	exec, err := graphql.Marshal(AliasQuery{})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if !strings.Contains(exec.Body, "headline:title") || !strings.Contains(exec.Body, "latest:comments(first:10)") {
+		t.Fatalf("unexpected body:\n%s", exec.Body)
+	}
+	var got *AliasQuery
+	if err := exec.Unmarshal([]byte(aliasResponse), &got); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if got == nil || got.Title != "My Title" || got.Editor.Name != "Bob" {
+		t.Errorf("unexpected result: %+v", got)
+	}
+
+	var other struct {
+		ID string `json:"id"`
+	}
+	if err := exec.Unmarshal([]byte(aliasResponse), &other); !errors.Is(err, graphql.ErrTypeMismatch) {
+		t.Errorf("expected type mismatch error when decoding into a different type, got %v", err)
+	}
+}
+
+func TestUnmarshalNulls(t *testing.T) {
+	got := AliasQuery{Editor: &AliasAuthor{ID: "old"}, Comments: []AliasAuthor{{ID: "old"}}}
+	if err := graphql.Unmarshal([]byte(`{"editor":null,"latest":null,"headline":null}`), &got); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if got.Editor != nil || got.Comments != nil {
+		t.Errorf("null should reset pointers and slices: %+v", got)
+	}
+}
+
+func TestUnmarshalTypeMismatch(t *testing.T) {
+	var got AliasQuery
+	err := graphql.Unmarshal([]byte(`{"author":{"id":10}}`), &got)
+	if err == nil {
+		t.Fatal("expected decode error, got nil")
+	}
+	if !strings.Contains(err.Error(), "author.id") {
+		t.Errorf("error should include the response path: %v", err)
+	}
+}
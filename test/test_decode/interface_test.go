@@ -0,0 +1,48 @@
+package test_decode
+
+import (
+	"testing"
+
+	graphql "github.com/lascyb/struct-to-graphql"
+)
+
+// 测试接口类型解码：公共字段始终解码，分支只填充与 __typename 匹配的一个
+type InterfaceProduct struct {
+	Title string `graphql:"title"`
+}
+
+type InterfaceCollection struct {
+	ProductsCount int `graphql:"productsCount"`
+}
+
+type InterfaceNode struct {
+	Typename string `json:"__typename" graphql:"__typename,interface"`
+	ID       string `json:"id" graphql:"id"`
+	InterfaceProduct
+	*InterfaceCollection `graphql:",type=Collection"`
+}
+
+type InterfaceQuery struct {
+	Nodes []InterfaceNode `json:"nodes" graphql:"nodes(ids:$ids:[ID!]!)"`
+}
+
+func TestUnmarshalInterface(t *testing.T) {
+	resp := `{"nodes":[
+		{"__typename":"InterfaceProduct","id":"p1","title":"Shirt"},
+		{"__typename":"Collection","id":"c1","productsCount":3}
+	]}`
+	var got InterfaceQuery
+	if err := graphql.Unmarshal([]byte(resp), &got); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if len(got.Nodes) != 2 {
+		t.Fatalf("got %d nodes, want 2", len(got.Nodes))
+	}
+	product, collection := got.Nodes[0], got.Nodes[1]
+	if product.ID != "p1" || product.Title != "Shirt" || product.InterfaceCollection != nil {
+		t.Errorf("unexpected product: %+v", product)
+	}
+	if collection.ID != "c1" || collection.InterfaceCollection == nil || collection.ProductsCount != 3 || collection.Title != "" {
+		t.Errorf("unexpected collection: %+v", collection)
+	}
+}
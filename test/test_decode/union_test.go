@@ -0,0 +1,98 @@
+package test_decode
+
+import (
+	"testing"
+
+	graphql "github.com/lascyb/struct-to-graphql"
+)
+
+// 测试联合类型解码：只填充与 __typename 匹配的分支
+// 各分支共有 id 字段，仅使用 graphql tag 即可（同名 json tag 在嵌入时会相互冲突）
+type UnionPost struct {
+	ID    string `graphql:"id"`
+	Title string `graphql:"title"`
+}
+
+type UnionAuthor struct {
+	ID   string `graphql:"id"`
+	Name string `graphql:"name"`
+}
+
+type UnionVideo struct {
+	ID  string `graphql:"id"`
+	URL string `graphql:"url"`
+}
+
+type UnionImage struct {
+	ID  string `graphql:"id"`
+	Src string `graphql:"src"`
+}
+
+type SearchResult struct {
+	Typename string `json:"__typename" graphql:"__typename,union"`
+	UnionPost
+	UnionAuthor
+	*UnionVideo
+	UnionImage `graphql:",type=Image"`
+}
+
+type SearchQuery struct {
+	Results []SearchResult `json:"results" graphql:"search(query:$query:String!),alias=results"`
+}
+
+const searchResponse = `{"results":[
+	{"__typename":"UnionPost","id":"p1","title":"Hello"},
+	{"__typename":"UnionAuthor","id":"a1","name":"Alice"},
+	{"__typename":"UnionVideo","id":"v1","url":"http://v"},
+	{"__typename":"Image","id":"i1","src":"http://i"},
+	{"__typename":"Unknown","id":"x1"}
+]}`
+
+func TestUnmarshalUnionBranches(t *testing.T) {
+	var got SearchQuery
+	if err := graphql.Unmarshal([]byte(searchResponse), &got); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if len(got.Results) != 5 {
+		t.Fatalf("got %d results, want 5", len(got.Results))
+	}
+
+	post := got.Results[0]
+	if post.Typename != "UnionPost" || post.UnionPost.ID != "p1" || post.UnionPost.Title != "Hello" {
+		t.Errorf("post branch not decoded: %+v", post)
+	}
+	if post.UnionAuthor.ID != "" || post.UnionVideo != nil || post.UnionImage.ID != "" {
+		t.Errorf("other branches should stay empty: %+v", post)
+	}
+
+	author := got.Results[1]
+	if author.UnionAuthor.ID != "a1" || author.UnionAuthor.Name != "Alice" || author.UnionPost.ID != "" {
+		t.Errorf("author branch not decoded: %+v", author)
+	}
+
+	video := got.Results[2]
+	if video.UnionVideo == nil || video.UnionVideo.ID != "v1" || video.UnionVideo.URL != "http://v" {
+		t.Errorf("pointer branch not decoded: %+v", video)
+	}
+
+	// type= 覆盖的类型名
+	image := got.Results[3]
+	if image.UnionImage.ID != "i1" || image.UnionImage.Src != "http://i" {
+		t.Errorf("type= branch not decoded: %+v", image)
+	}
+
+	unknown := got.Results[4]
+	if unknown.Typename != "Unknown" || unknown.UnionPost.ID != "" || unknown.UnionAuthor.ID != "" || unknown.UnionVideo != nil || unknown.UnionImage.ID != "" {
+		t.Errorf("unknown typename should leave all branches empty: %+v", unknown)
+	}
+}
+
+func TestUnmarshalUnionResetsPreviousBranch(t *testing.T) {
+	got := SearchResult{UnionAuthor: UnionAuthor{ID: "stale"}, UnionVideo: &UnionVideo{ID: "stale"}}
+	if err := graphql.Unmarshal([]byte(`{"__typename":"UnionPost","id":"p1"}`), &got); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if got.UnionPost.ID != "p1" || got.UnionAuthor.ID != "" || got.UnionVideo != nil {
+		t.Errorf("unexpected result: %+v", got)
+	}
+}
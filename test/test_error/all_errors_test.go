@@ -0,0 +1,120 @@
+package test_error
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	graphql "github.com/lascyb/struct-to-graphql"
+	"github.com/lascyb/struct-to-graphql/core"
+)
+
+// AllErrorsMember 联合类型的命名分支
+type AllErrorsMember struct {
+	ID string `graphql:"id"`
+}
+
+// AllErrorsUnion 同时包含非嵌入分支与匿名结构体分支的联合类型
+type AllErrorsUnion struct {
+	Typename string `graphql:"__typename,union"`
+	AllErrorsMember
+	Plain     AllErrorsMember `graphql:"plain"`
+	Anonymous struct {
+		Name string `graphql:"name"`
+	}
+}
+
+// AllErrorsQuery 包含多种问题的查询结构体，AllErrors 模式下应一次返回全部错误
+type AllErrorsQuery struct {
+	Content AllErrorsUnion `graphql:"content"`
+	Items   []struct {
+		ID string `graphql:"id"`
+	} `graphql:"items(query::String!)"`
+	Products []struct {
+		ID string `graphql:"id"`
+	} `graphql:"products(author:$author:Int!)"`
+	Contents []struct {
+		ID string `graphql:"id"`
+	} `graphql:"contents(author:$author:String!,after:$after)"`
+}
+
+func TestAllErrors(t *testing.T) {
+	// 默认在第一个错误处返回
+	_, err := graphql.Marshal(AllErrorsQuery{})
+	if n := len(graphql.FieldErrors(err)); n != 1 {
+		t.Fatalf("expected a single error by default, got %d: %v", n, err)
+	}
+
+	_, err = graphql.MarshalWithOptions(AllErrorsQuery{}, graphql.Options{AllErrors: true})
+	if err == nil {
+		t.Fatal("expected errors, got nil")
+	}
+	type location struct {
+		kind   error
+		goPath string
+	}
+	var got []location
+	for _, fieldErr := range graphql.FieldErrors(err) {
+		got = append(got, location{fieldErr.Kind, fieldErr.GoPath})
+	}
+	want := []location{
+		{graphql.ErrInvalidUnion, "AllErrorsQuery.Content.Plain"},
+		{graphql.ErrInvalidUnion, "AllErrorsQuery.Content.Anonymous"},
+		{graphql.ErrInvalidTag, "AllErrorsQuery.Items"},
+		{graphql.ErrVariableTypeConflict, "AllErrorsQuery.Contents"},
+		{graphql.ErrMissingVariableType, "AllErrorsQuery.Contents"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d errors, want %d:\n%v", len(got), len(want), err)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("error %d: got %v at %s, want %v at %s", i, got[i].kind, got[i].goPath, want[i].kind, want[i].goPath)
+		}
+	}
+	for _, kind := range []error{graphql.ErrInvalidUnion, graphql.ErrInvalidTag, graphql.ErrVariableTypeConflict, graphql.ErrMissingVariableType} {
+		if !errors.Is(err, kind) {
+			t.Errorf("errors.Is(err, %v) should be true", kind)
+		}
+	}
+
+	// 缺少类型的变量在生成时即报错，没有问题时正常返回
+	exec, err := graphql.MarshalWithOptions(MissingVariableTypeError{}, graphql.Options{AllErrors: true})
+	if !errors.Is(err, graphql.ErrMissingVariableType) || exec != nil {
+		t.Errorf("expected missing variable type error, got %v", err)
+	}
+	if _, err := graphql.MarshalWithOptions(AllErrorsMember{}, graphql.Options{AllErrors: true}); err != nil {
+		t.Errorf("expected success, got %v", err)
+	}
+}
+
+// AllErrorsAnonymousUnion 命名分支之后是无法输出的匿名结构体分支
+type AllErrorsAnonymousUnion struct {
+	Typename string `graphql:"__typename,union"`
+	AllErrorsMember
+	Anonymous struct {
+		Name string `graphql:"name"`
+	}
+}
+
+func TestAllErrorsSkipsInvalidBranches(t *testing.T) {
+	// 收集模式下跳过的联合类型分支不应在返回的查询体中留下不完整的 "... on"
+	typeParser, err := core.NewParserWithOptions(core.Options{AllErrors: true}).ParseType(reflect.TypeFor[struct {
+		Content AllErrorsAnonymousUnion `graphql:"content"`
+	}]())
+	if err != nil {
+		t.Fatalf("ParseType failed: %v", err)
+	}
+	builder := core.NewBuilderWithOptions(core.Options{AllErrors: true})
+	body, err := builder.Build(typeParser)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if errs := builder.Errors(); len(errs) != 1 || !errors.Is(errs[0], graphql.ErrInvalidUnion) {
+		t.Errorf("expected a single ErrInvalidUnion, got %v", errs)
+	}
+	want := "{\n  content{\n    __typename\n    ... on AllErrorsMember {\n      id\n    }\n  }\n}"
+	if body != want {
+		t.Errorf("got body:\n%s\nwant:\n%s", body, want)
+	}
+}
@@ -1,6 +1,7 @@
 package test_error
 
 import (
+	"errors"
 	"strings"
 	"testing"
 
@@ -24,7 +25,7 @@ func TestCommonMisuse_UnionAnonymousMemberShouldFail(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error for anonymous struct in union member, got nil")
 	}
-	if !strings.Contains(err.Error(), "embedded struct field") && !strings.Contains(err.Error(), "named struct type") {
+	if !errors.Is(err, graphql.ErrInvalidUnion) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
@@ -44,7 +45,7 @@ func TestCommonMisuse_VariableTypeConflictShouldFail(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected variable type conflict error, got nil")
 	}
-	if !strings.Contains(err.Error(), "类型不统一") && !strings.Contains(err.Error(), "author") {
+	if !errors.Is(err, graphql.ErrVariableTypeConflict) || !strings.Contains(err.Error(), "author") {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
@@ -61,7 +62,7 @@ func TestCommonMisuse_EmptyLiteralArgumentShouldFail(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected empty literal argument error, got nil")
 	}
-	if !strings.Contains(err.Error(), "参数值不能定义为空") && !strings.Contains(err.Error(), "unexpected token") {
+	if !errors.Is(err, graphql.ErrInvalidTag) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
@@ -82,7 +83,7 @@ func TestCommonMisuse_MissingVariableTypeShouldFail(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected missing variable type error, got nil")
 	}
-	if !strings.Contains(err.Error(), "缺少类型定义") {
+	if !errors.Is(err, graphql.ErrMissingVariableType) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
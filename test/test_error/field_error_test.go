@@ -0,0 +1,130 @@
+package test_error
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	graphql "github.com/lascyb/struct-to-graphql"
+)
+
+// FieldErrorQuery 嵌套字段中的变量类型冲突，错误应携带 Go 字段路径与 GraphQL 响应路径
+type FieldErrorQuery struct {
+	Shop struct {
+		Products struct {
+			Nodes []struct {
+				ID string `graphql:"id"`
+			} `graphql:"nodes(first:$first:Int!)"`
+		} `graphql:"items:products(first:$first:String)"`
+	} `graphql:"shop"`
+}
+
+type FieldErrorTagQuery struct {
+	Shop struct {
+		Name string `graphql:"name(locale:{)"`
+	} `graphql:"shop"`
+}
+
+func TestFieldErrorPaths(t *testing.T) {
+	_, err := graphql.Marshal(FieldErrorQuery{})
+	var fieldErr *graphql.FieldError
+	if !errors.As(err, &fieldErr) {
+		t.Fatalf("expected *FieldError, got %v", err)
+	}
+	if fieldErr.Kind != graphql.ErrVariableTypeConflict || !errors.Is(err, graphql.ErrVariableTypeConflict) {
+		t.Errorf("unexpected kind %v", fieldErr.Kind)
+	}
+	if fieldErr.GoPath != "FieldErrorQuery.Shop.Products.Nodes" {
+		t.Errorf("got GoPath %q", fieldErr.GoPath)
+	}
+	if fieldErr.GraphQLPath != "shop.items.nodes" {
+		t.Errorf("got GraphQLPath %q", fieldErr.GraphQLPath)
+	}
+	if fieldErr.Subject != "$first" {
+		t.Errorf("got Subject %q", fieldErr.Subject)
+	}
+	if !strings.HasPrefix(err.Error(), "FieldErrorQuery.Shop.Products.Nodes: ") {
+		t.Errorf("message should start with the Go path: %v", err)
+	}
+
+	_, err = graphql.Marshal(FieldErrorTagQuery{})
+	if !errors.As(err, &fieldErr) || !errors.Is(err, graphql.ErrInvalidTag) {
+		t.Fatalf("expected invalid tag error, got %v", err)
+	}
+	if fieldErr.GoPath != "FieldErrorTagQuery.Shop.Name" || fieldErr.Subject != "name(locale:{)" || fieldErr.Err == nil {
+		t.Errorf("unexpected error %#v", fieldErr)
+	}
+}
+
+func TestSetErrorFormatter(t *testing.T) {
+	graphql.SetErrorFormatter(func(e *graphql.FieldError) string {
+		if errors.Is(e.Kind, graphql.ErrVariableTypeConflict) {
+			return e.GoPath + "：变量 " + e.Subject + " 的类型不统一"
+		}
+		return ""
+	})
+	defer graphql.SetErrorFormatter(nil)
+
+	_, err := graphql.Marshal(FieldErrorQuery{})
+	if want := "FieldErrorQuery.Shop.Products.Nodes：变量 $first 的类型不统一"; err == nil || err.Error() != want {
+		t.Errorf("got %v, want %s", err, want)
+	}
+	// 返回空字符串时使用默认信息
+	exec, err := graphql.Marshal(MissingVariableTypeError{})
+	if err != nil {
+		t.Fatalf("marshal should succeed, got: %v", err)
+	}
+	if _, err = exec.Query("MissingType"); err == nil || !strings.Contains(err.Error(), "has no type") {
+		t.Errorf("expected default message, got %v", err)
+	}
+
+	graphql.SetErrorFormatter(nil)
+	_, err = graphql.Marshal(FieldErrorQuery{})
+	if err == nil || !strings.Contains(err.Error(), "conflicting types") {
+		t.Errorf("formatter should be reset, got %v", err)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	type Query struct {
+		Shop struct {
+			Products []struct {
+				Count int `graphql:"count"`
+			} `graphql:"items:products"`
+		} `graphql:"shop"`
+	}
+	exec, err := graphql.Marshal(Query{})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var got Query
+	err = exec.Unmarshal([]byte(`{"shop":{"items":[{"count":"many"}]}}`), &got)
+	var fieldErr *graphql.FieldError
+	if !errors.As(err, &fieldErr) || !errors.Is(err, graphql.ErrDecode) || fieldErr.GraphQLPath != "shop.items.0.count" {
+		t.Fatalf("unexpected error %#v", err)
+	}
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) {
+		t.Errorf("expected the underlying json error, got %v", err)
+	}
+
+	if err := exec.Unmarshal([]byte(`{}`), got); !errors.Is(err, graphql.ErrTypeMismatch) {
+		t.Errorf("expected type mismatch for a non-pointer target, got %v", err)
+	}
+	if err := graphql.Unmarshal([]byte(`{}`), nil); !errors.Is(err, graphql.ErrNilInput) {
+		t.Errorf("expected nil input error, got %v", err)
+	}
+
+	graphql.SetErrorFormatter(func(e *graphql.FieldError) string {
+		if errors.Is(e.Kind, graphql.ErrDecode) {
+			return "响应解码失败：" + e.GraphQLPath
+		}
+		return ""
+	})
+	defer graphql.SetErrorFormatter(nil)
+	err = exec.Unmarshal([]byte(`{"shop":{"items":[{"count":"many"}]}}`), &got)
+	if want := "响应解码失败：shop.items.0.count"; err == nil || err.Error() != want {
+		t.Errorf("got %v, want %s", err, want)
+	}
+}
@@ -0,0 +1,114 @@
+package test_flag
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	graphql "github.com/lascyb/struct-to-graphql"
+)
+
+// FlagDepthCategory 自引用类型，depth=2 表示子分类最多展开两层
+type FlagDepthCategory struct {
+	Name     string              `json:"name" graphql:"name"`
+	Children []FlagDepthCategory `json:"children" graphql:"children,depth=2"`
+}
+
+type FlagDepthQuery struct {
+	Categories []FlagDepthCategory `json:"categories" graphql:"categories"`
+}
+
+// FlagDepthComment 未设置 depth 的自引用类型，由 Options.MaxDepth 控制展开层数
+type FlagDepthComment struct {
+	ID      string              `json:"id" graphql:"id"`
+	Replies []*FlagDepthComment `json:"replies" graphql:"replies"`
+}
+
+type FlagDepthCommentQuery struct {
+	Comments []FlagDepthComment `json:"comments" graphql:"comments"`
+}
+
+func TestFlagDepth_UnrollsSelfReference(t *testing.T) {
+	exec, err := graphql.Marshal(FlagDepthQuery{})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	want := `{
+  categories{
+    name
+    children{
+      name
+      children{
+        name
+      }
+    }
+  }
+}`
+	if exec.Body != want {
+		t.Errorf("got:\n%s\nwant:\n%s", exec.Body, want)
+	}
+
+	var result FlagDepthQuery
+	data := `{"categories":[{"name":"a","children":[{"name":"b","children":[{"name":"c"}]}]}]}`
+	if err := exec.Unmarshal([]byte(data), &result); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if got := result.Categories[0].Children[0].Children[0].Name; got != "c" {
+		t.Errorf("got leaf %q, want c", got)
+	}
+}
+
+func TestFlagDepth_MaxDepthOption(t *testing.T) {
+	if _, err := graphql.Marshal(FlagDepthCommentQuery{}); !errors.Is(err, graphql.ErrCircularReference) {
+		t.Fatalf("expected circular reference error without depth, got %v", err)
+	}
+	exec, err := graphql.MarshalWithOptions(FlagDepthCommentQuery{}, graphql.Options{MaxDepth: 3})
+	if err != nil {
+		t.Fatalf("MarshalWithOptions failed: %v", err)
+	}
+	if n := strings.Count(exec.Body, "replies{"); n != 3 {
+		t.Errorf("expected 3 levels of replies, got %d:\n%s", n, exec.Body)
+	}
+	// 字段上的 depth 优先于 Options.MaxDepth
+	exec, err = graphql.MarshalWithOptions(FlagDepthQuery{}, graphql.Options{MaxDepth: 5})
+	if err != nil {
+		t.Fatalf("MarshalWithOptions failed: %v", err)
+	}
+	if n := strings.Count(exec.Body, "children{"); n != 2 {
+		t.Errorf("expected 2 levels of children, got %d:\n%s", n, exec.Body)
+	}
+}
+
+func TestFlagDepth_InvalidDepth(t *testing.T) {
+	type Node struct {
+		ID    string `graphql:"id"`
+		Child *Node  `graphql:"child,depth=x"`
+	}
+	if _, err := graphql.Marshal(struct {
+		Node Node `graphql:"node"`
+	}{}); !errors.Is(err, graphql.ErrInvalidTag) {
+		t.Errorf("expected invalid tag error, got %v", err)
+	}
+}
+
+func TestFlagDepth_PackageUnmarshal(t *testing.T) {
+	// 包级 Unmarshal 不读取选项，未设置 depth 的自引用类型按响应数据的实际层数解码
+	data := `{"comments":[{"id":"1","replies":[{"id":"2","replies":[{"id":"3","replies":[{"id":"4"}]}]}]}]}`
+	var comments FlagDepthCommentQuery
+	if err := graphql.Unmarshal([]byte(data), &comments); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if got := comments.Comments[0].Replies[0].Replies[0].Replies[0].ID; got != "4" {
+		t.Errorf("got leaf %q, want 4", got)
+	}
+
+	// depth=N 的字段与生成的查询一致，只解码 N 层
+	data = `{"categories":[{"name":"a","children":[{"name":"b","children":[{"name":"c","children":[{"name":"d"}]}]}]}]}`
+	var categories FlagDepthQuery
+	if err := graphql.Unmarshal([]byte(data), &categories); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if leaf := categories.Categories[0].Children[0].Children[0]; leaf.Name != "c" || leaf.Children != nil {
+		t.Errorf("got leaf %+v, want c without children", leaf)
+	}
+}
@@ -0,0 +1,137 @@
+package test_flag
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	graphql "github.com/lascyb/struct-to-graphql"
+)
+
+// FlagFragmentImage 命名结构体，用于测试 fragment / nofragment 标记与 Fragment 策略
+type FlagFragmentImage struct {
+	URL string `graphql:"url"`
+}
+
+// FlagFragmentSEO 以匿名嵌入方式平铺到父级的命名结构体
+type FlagFragmentSEO struct {
+	Title string `graphql:"title"`
+}
+
+type FlagFragmentQuery struct {
+	Product struct {
+		ID              string            `graphql:"id"`
+		Image           FlagFragmentImage `graphql:"image,fragment=ImageParts"`
+		FlagFragmentSEO `graphql:",fragment=SEOParts,type=SEO"`
+		Variant         struct {
+			SKU string `graphql:"sku"`
+		} `graphql:"variant,fragment=VariantParts,type=ProductVariant"`
+	} `graphql:"product"`
+}
+
+func TestFlagFragment_Explicit(t *testing.T) {
+	exec, err := graphql.Marshal(FlagFragmentQuery{})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	query, err := exec.Query("FlagFragment")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	want := `fragment ImageParts on FlagFragmentImage{
+  url
+}
+fragment SEOParts on SEO{
+  title
+}
+fragment VariantParts on ProductVariant{
+  sku
+}
+query FlagFragment {
+  product{
+    id
+    image{ ...ImageParts }
+    ...SEOParts
+    variant{ ...VariantParts }
+  }
+}`
+	if query != want {
+		t.Errorf("got:\n%s\nwant:\n%s", query, want)
+	}
+}
+
+type FlagFragmentReusedQuery struct {
+	Image  FlagFragmentImage `graphql:"image"`
+	Banner FlagFragmentImage `graphql:"banner,nofragment"`
+	Icon   FlagFragmentImage `graphql:"icon"`
+}
+
+func TestFlagFragment_NoFragment(t *testing.T) {
+	exec, err := graphql.Marshal(FlagFragmentReusedQuery{})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	want := `{
+  image{ ...Test_flagFlagFragmentImage }
+  banner{
+    url
+  }
+  icon{ ...Test_flagFlagFragmentImage }
+}`
+	if exec.Body != want {
+		t.Errorf("got:\n%s\nwant:\n%s", exec.Body, want)
+	}
+}
+
+func TestFlagFragment_Policy(t *testing.T) {
+	type Query struct {
+		Image FlagFragmentImage `graphql:"image"`
+		Owner struct {
+			Name string `graphql:"name"`
+		} `graphql:"owner"`
+	}
+	exec, err := graphql.MarshalWithOptions(Query{}, graphql.Options{Fragments: graphql.FragmentAlways})
+	if err != nil {
+		t.Fatalf("MarshalWithOptions failed: %v", err)
+	}
+	// 匿名结构体没有类型名，FragmentAlways 下仍直接展开
+	if len(exec.Fragments) != 1 || !strings.Contains(exec.Body, "image{ ...Test_flagFlagFragmentImage }") {
+		t.Errorf("FragmentAlways: got fragments %d, body:\n%s", len(exec.Fragments), exec.Body)
+	}
+
+	exec, err = graphql.MarshalWithOptions(FlagFragmentReusedQuery{}, graphql.Options{Fragments: graphql.FragmentNever})
+	if err != nil {
+		t.Fatalf("MarshalWithOptions failed: %v", err)
+	}
+	if len(exec.Fragments) != 0 || strings.Count(exec.Body, "url") != 3 {
+		t.Errorf("FragmentNever: got fragments %d, body:\n%s", len(exec.Fragments), exec.Body)
+	}
+
+	// 字段标记优先于策略
+	exec, err = graphql.MarshalWithOptions(FlagFragmentQuery{}, graphql.Options{Fragments: graphql.FragmentNever})
+	if err != nil {
+		t.Fatalf("MarshalWithOptions failed: %v", err)
+	}
+	if len(exec.Fragments) != 3 {
+		t.Errorf("explicit fragments should be kept with FragmentNever, got %d", len(exec.Fragments))
+	}
+}
+
+func TestFlagFragment_Errors(t *testing.T) {
+	type MissingType struct {
+		Owner struct {
+			Name string `graphql:"name"`
+		} `graphql:"owner,fragment=OwnerParts"`
+	}
+	if _, err := graphql.Marshal(MissingType{}); !errors.Is(err, graphql.ErrInvalidTag) {
+		t.Errorf("expected invalid tag error for anonymous fragment without type=, got %v", err)
+	}
+
+	type Conflict struct {
+		Image FlagFragmentImage `graphql:"image,fragment=A"`
+		Icon  FlagFragmentImage `graphql:"icon,fragment=B"`
+	}
+	if _, err := graphql.Marshal(Conflict{}); !errors.Is(err, graphql.ErrInvalidTag) {
+		t.Errorf("expected conflict error, got %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package test_flag
+
+import (
+	"strings"
+	"testing"
+
+	graphql "github.com/lascyb/struct-to-graphql"
+)
+
+// FlagInterfaceProduct 接口实现：商品
+type FlagInterfaceProduct struct {
+	Title string `graphql:"title"`
+	Price string `graphql:"price"`
+}
+
+// FlagInterfaceCollection 接口实现：集合（通过 type flag 覆盖输出类型名）
+type FlagInterfaceCollection struct {
+	ProductsCount int `graphql:"productsCount"`
+}
+
+// FlagInterfaceNode 使用 interface flag：公共字段直接选择，嵌入的命名结构体作为 "... on" 分支
+type FlagInterfaceNode struct {
+	Typename string `graphql:"__typename,interface"`
+	ID       string `graphql:"id"`
+	Handle   string `graphql:"handle,alias=slug"`
+	FlagInterfaceProduct
+	FlagInterfaceCollection `graphql:",type=Collection"`
+}
+
+type FlagInterfaceQuery struct {
+	Node FlagInterfaceNode `graphql:"node(id:$id:ID!)"`
+}
+
+func TestFlagInterface_CommonFieldsAndBranches(t *testing.T) {
+	exec, err := graphql.Marshal(FlagInterfaceQuery{})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	t.Logf("Body:\n%s", exec.Body)
+	want := `{
+  node(id:$id){
+    __typename
+    id
+    slug:handle
+    ... on FlagInterfaceProduct {
+      title
+      price
+    }
+    ... on Collection {
+      productsCount
+    }
+  }
+}`
+	if exec.Body != want {
+		t.Errorf("got body:\n%s\nwant:\n%s", exec.Body, want)
+	}
+}
+
+// FlagInterfaceStructField 接口类型中未嵌入的结构体字段属于公共字段
+type FlagInterfaceStructField struct {
+	Typename string `graphql:"__typename,interface"`
+	ID       string `graphql:"id"`
+	Owner    struct {
+		Name string `graphql:"name"`
+	} `graphql:"owner"`
+}
+
+func TestFlagInterface_PlainStructFieldIsCommonField(t *testing.T) {
+	exec, err := graphql.Marshal(struct {
+		Node FlagInterfaceStructField `graphql:"node"`
+	}{})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	// 非嵌入字段即使是结构体也只是公共字段，不会生成 "... on"
+	if strings.Contains(exec.Body, "... on") || !strings.Contains(exec.Body, "owner{") {
+		t.Errorf("unexpected body:\n%s", exec.Body)
+	}
+}
+
+// FlagInterfaceAndUnion 同时标记 union 与 interface 应报错
+type FlagInterfaceAndUnion struct {
+	Typename string `graphql:"__typename,union,interface"`
+	FlagInterfaceProduct
+}
+
+func TestFlagInterface_UnionAndInterfaceShouldFail(t *testing.T) {
+	_, err := graphql.Marshal(struct {
+		Node FlagInterfaceAndUnion `graphql:"node"`
+	}{})
+	if err == nil || !strings.Contains(err.Error(), "both a union and an interface") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
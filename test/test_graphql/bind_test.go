@@ -0,0 +1,128 @@
+package test_graphql
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	graphql "github.com/lascyb/struct-to-graphql"
+)
+
+// 测试按变量名或字段路径为变量赋值
+type BindQuery struct {
+	Items struct {
+		Nodes []struct {
+			ID string `json:"id" graphql:"id"`
+		} `json:"nodes" graphql:"nodes(first:$:Int!)"`
+	} `json:"items" graphql:"items(query:$query:String,sortKey:$sortKey:String=\"TITLE\")"`
+	Product struct {
+		ID string `json:"id" graphql:"id"`
+	} `json:"product" graphql:"product(id:$id:ID!)"`
+}
+
+func TestBindByNameAndPath(t *testing.T) {
+	exec, err := graphql.Marshal(BindQuery{})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	data, err := exec.Bind(map[string]any{
+		"$id":         "gid://1",
+		"items/nodes": 20,
+	})
+	if err != nil {
+		t.Fatalf("Bind failed: %v", err)
+	}
+	// 可空且无默认值的 $query 不输出，$sortKey 使用默认值
+	want := `{"id":"gid://1","items_nodes_first":20,"sortKey":"TITLE"}`
+	if string(data) != want {
+		t.Errorf("got %s, want %s", data, want)
+	}
+}
+
+func TestBindErrors(t *testing.T) {
+	exec, err := graphql.Marshal(BindQuery{})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	_, err = exec.Bind(map[string]any{
+		"unknown": 1,
+		"items":   "ambiguous",
+		"query":   "a",
+	})
+	if err == nil {
+		t.Fatal("expected errors, got nil")
+	}
+	for _, want := range []string{"unknown", "$id", "$items_nodes_first"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("missing %q in error:\n%v", want, err)
+		}
+	}
+	for _, kind := range []error{graphql.ErrUnknownVariable, graphql.ErrAmbiguousPath, graphql.ErrMissingValue} {
+		if !errors.Is(err, kind) {
+			t.Errorf("expected %v in error:\n%v", kind, err)
+		}
+	}
+
+	_, err = exec.Bind(map[string]any{"id": nil, "items_nodes_first": 1})
+	if !errors.Is(err, graphql.ErrNullValue) {
+		t.Errorf("expected null error, got %v", err)
+	}
+	_, err = exec.Bind(map[string]any{"id": "1", "$id": "2", "items_nodes_first": 1})
+	if !errors.Is(err, graphql.ErrDuplicateValue) {
+		t.Errorf("expected duplicate error, got %v", err)
+	}
+}
+
+func TestBindStartsFromVarsStruct(t *testing.T) {
+	type Query struct {
+		Product struct {
+			ID string `json:"id" graphql:"id"`
+		} `json:"product" graphql:"product(id:$id,locale:$locale)"`
+	}
+	exec, err := graphql.MarshalWithVars(Query{}, struct {
+		ID     string `json:"id"`
+		Locale string
+	}{ID: "1", Locale: "en"})
+	if err != nil {
+		t.Fatalf("MarshalWithVars failed: %v", err)
+	}
+	data, err := exec.Bind(map[string]any{"locale": "fr"})
+	if err != nil {
+		t.Fatalf("Bind failed: %v", err)
+	}
+	if string(data) != `{"id":"1","locale":"fr"}` {
+		t.Errorf("unexpected variables: %s", data)
+	}
+}
+
+func TestBindRejectsNullFromVarsStruct(t *testing.T) {
+	type Query struct {
+		Product struct {
+			ID string `json:"id" graphql:"id"`
+		} `json:"product" graphql:"product(id:$id:ID!,locale:$locale:String)"`
+	}
+	// 变量结构体中的 nil 指针同样受非空类型约束
+	exec, err := graphql.MarshalWithVarsOptions(Query{}, struct {
+		ID     *string `json:"id"`
+		Locale *string
+	}{}, graphql.Options{Indent: "\t"})
+	if err != nil {
+		t.Fatalf("MarshalWithVarsOptions failed: %v", err)
+	}
+	if !strings.Contains(exec.Body, "\n\tproduct(") {
+		t.Errorf("options ignored:\n%s", exec.Body)
+	}
+	if _, err := exec.Bind(nil); !errors.Is(err, graphql.ErrNullValue) {
+		t.Errorf("expected null value error, got %v", err)
+	}
+	data, err := exec.Bind(map[string]any{"id": "1"})
+	if err != nil {
+		t.Fatalf("Bind failed: %v", err)
+	}
+	if string(data) != `{"id":"1","locale":null}` {
+		t.Errorf("unexpected variables: %s", data)
+	}
+	if _, err := exec.Bind(map[string]any{"id": (*string)(nil)}); !errors.Is(err, graphql.ErrNullValue) {
+		t.Errorf("expected null value error for a typed nil, got %v", err)
+	}
+}
@@ -0,0 +1,237 @@
+package test_graphql
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	graphql "github.com/lascyb/struct-to-graphql"
+)
+
+// 测试按类型缓存的生成结果：并发安全、返回独立副本、选项参与缓存键
+type CacheImage struct {
+	URL string `json:"url" graphql:"url"`
+}
+
+type CacheQuery struct {
+	Products struct {
+		Nodes []struct {
+			ID     string     `json:"id" graphql:"id"`
+			Title  string     `json:"title" graphql:"title"`
+			Image  CacheImage `json:"image" graphql:"image"`
+			Banner CacheImage `json:"banner" graphql:"banner"`
+		} `json:"nodes" graphql:"nodes"`
+	} `json:"products" graphql:"products(first:$first:Int!,query:$query:String)"`
+	Product struct {
+		ID string `json:"id" graphql:"id"`
+	} `json:"product" graphql:"product(id:$id:ID!)"`
+}
+
+// cacheDistinctType 返回字段名不同的结构体类型，用于并发生成互不相同的类型
+func cacheDistinctType(i int) reflect.Type {
+	return reflect.StructOf([]reflect.StructField{{
+		Name: fmt.Sprintf("Field%d", i),
+		Type: reflect.TypeOf(CacheImage{}),
+		Tag:  reflect.StructTag(fmt.Sprintf(`graphql:"field%d(id:$id%d:ID!)"`, i, i)),
+	}})
+}
+
+func TestMarshalCacheReturnsIndependentCopies(t *testing.T) {
+	first, err := graphql.Marshal(CacheQuery{})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	want, err := first.Query("Cache")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	// 修改返回值不影响之后的调用
+	first.Variables[0].Type = "String"
+	first.Variables[0].Paths[0] = "changed"
+	first.Fragments[0].Name = "Changed"
+
+	second, err := graphql.Marshal(CacheQuery{})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	got, err := second.Query("Cache")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if got != want {
+		t.Errorf("cached result was modified by the caller:\n%s\nwant:\n%s", got, want)
+	}
+	if second.Variables[0].Paths[0] != "products" {
+		t.Errorf("got path %q", second.Variables[0].Paths[0])
+	}
+
+	// 选项参与缓存键
+	indented, err := graphql.MarshalWithOptions(CacheQuery{}, graphql.Options{Indent: "\t"})
+	if err != nil {
+		t.Fatalf("MarshalWithOptions failed: %v", err)
+	}
+	if !strings.Contains(indented.Body, "\n\tproducts") {
+		t.Errorf("indent option ignored by the cache:\n%s", indented.Body)
+	}
+	uncached, err := graphql.MarshalWithOptions(CacheQuery{}, graphql.Options{DisableCache: true})
+	if err != nil {
+		t.Fatalf("MarshalWithOptions failed: %v", err)
+	}
+	if uncached.Body != second.Body {
+		t.Errorf("uncached body differs:\n%s\nwant:\n%s", uncached.Body, second.Body)
+	}
+}
+
+func TestTypeParserIsIndependentOfCache(t *testing.T) {
+	first, err := graphql.Marshal(CacheQuery{})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	// 修改返回的解析结果不影响缓存的生成结果与之后的解码
+	typeParser := first.TypeParser()
+	if first.TypeParser() != typeParser {
+		t.Errorf("TypeParser should return the same copy for the same Graphql")
+	}
+	// 副本保留共享的子类型
+	nodes := typeParser.Fields[0].TypeParser.Fields[0].TypeParser
+	if nodes.Fields[2].TypeParser != nodes.Fields[3].TypeParser {
+		t.Errorf("shared CacheImage parser should stay shared in the copy")
+	}
+	typeParser.Fields[0].FieldName = "changed"
+	typeParser.Fields = nil
+
+	second, err := graphql.Marshal(CacheQuery{})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if fields := second.TypeParser().Fields; len(fields) != 2 || fields[0].FieldName != "products" {
+		t.Errorf("cached parser was modified by the caller: %+v", fields)
+	}
+	var value CacheQuery
+	if err := second.Unmarshal([]byte(`{"product":{"id":"1"}}`), &value); err != nil || value.Product.ID != "1" {
+		t.Errorf("Unmarshal: got %+v, %v", value, err)
+	}
+}
+
+func TestMarshalCacheConcurrent(t *testing.T) {
+	want, err := graphql.MarshalWithOptions(CacheQuery{}, graphql.Options{DisableCache: true})
+	if err != nil {
+		t.Fatalf("MarshalWithOptions failed: %v", err)
+	}
+	const goroutines = 64
+	var wg sync.WaitGroup
+	errs := make(chan error, goroutines*2)
+	for i := range goroutines {
+		wg.Add(2)
+		// 共享类型：并发读取同一缓存项，并修改各自的副本
+		go func() {
+			defer wg.Done()
+			for range 20 {
+				exec, err := graphql.Marshal(CacheQuery{})
+				if err != nil {
+					errs <- err
+					return
+				}
+				if exec.Body != want.Body {
+					errs <- fmt.Errorf("got body:\n%s", exec.Body)
+					return
+				}
+				exec.Variables[0].Type = "String"
+				exec.Fragments[0].Body = ""
+			}
+		}()
+		// 互不相同的类型：并发写入不同的缓存项
+		go func() {
+			defer wg.Done()
+			exec, err := graphql.Marshal(reflect.New(cacheDistinctType(i)).Elem().Interface())
+			if err != nil {
+				errs <- err
+				return
+			}
+			if want := fmt.Sprintf("$id%d", i); len(exec.Variables) != 1 || exec.Variables[0].Name != want {
+				errs <- fmt.Errorf("got variables %v, want %s", exec.Variables, want)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestMarshalWithVarsCached(t *testing.T) {
+	type Vars struct {
+		First int
+		Query *string
+		ID    string `graphql:"id"`
+	}
+	for i := range 2 {
+		exec, err := graphql.MarshalWithVars(CacheQuery{}, Vars{First: i})
+		if err != nil {
+			t.Fatalf("MarshalWithVars failed: %v", err)
+		}
+		if got := fmt.Sprint(exec.Values["first"]); got != fmt.Sprint(i) {
+			t.Errorf("call %d: got first=%s", i, got)
+		}
+	}
+}
+
+func TestSetCacheSize(t *testing.T) {
+	t.Cleanup(func() { graphql.SetCacheSize(graphql.DefaultCacheSize) })
+	want, err := graphql.MarshalWithOptions(CacheQuery{}, graphql.Options{DisableCache: true})
+	if err != nil {
+		t.Fatalf("MarshalWithOptions failed: %v", err)
+	}
+	// 容量为 1 时不同类型交替生成，缓存项不断被淘汰，结果仍然正确
+	for _, size := range []int{1, 0} {
+		graphql.SetCacheSize(size)
+		for i := range 4 {
+			exec, err := graphql.Marshal(CacheQuery{})
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+			if exec.Body != want.Body {
+				t.Errorf("size %d: got body:\n%s", size, exec.Body)
+			}
+			distinct, err := graphql.Marshal(reflect.New(cacheDistinctType(i)).Elem().Interface())
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+			if len(distinct.Variables) != 1 || distinct.Variables[0].Name != fmt.Sprintf("$id%d", i) {
+				t.Errorf("size %d: got variables %v", size, distinct.Variables)
+			}
+		}
+	}
+}
+
+func BenchmarkMarshal(b *testing.B) {
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := graphql.Marshal(CacheQuery{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMarshalDisableCache(b *testing.B) {
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := graphql.MarshalWithOptions(CacheQuery{}, graphql.Options{DisableCache: true}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMarshalParallel(b *testing.B) {
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := graphql.Marshal(CacheQuery{}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
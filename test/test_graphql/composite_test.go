@@ -0,0 +1,68 @@
+package test_graphql
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	graphql "github.com/lascyb/struct-to-graphql"
+)
+
+// 测试输入对象与列表字面量参数
+type CompositeQuery struct {
+	Products struct {
+		Nodes []struct {
+			ID string `json:"id" graphql:"id"`
+		} `json:"nodes" graphql:"nodes"`
+	} `json:"products" graphql:"products(sortKey:{field:CREATED_AT,reverse:true},ids:[\"a\",\"b\"],first:10)"`
+	Orders struct {
+		ID string `json:"id" graphql:"id"`
+	} `json:"orders" graphql:"orders(filter:{status:$status:Status!,tags:[$:String!,\"vip\"],range:{from:$from:Int=0,to:null}}),@include(if:$withOrders:Boolean!)"`
+}
+
+func TestCompositeLiteralArguments(t *testing.T) {
+	exec, err := graphql.Marshal(CompositeQuery{})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	query, err := exec.Query("Composite")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	for _, want := range []string{
+		"query Composite($status:Status!,$orders_filter_tags_0:String!,$from:Int=0,$withOrders:Boolean!) {",
+		`products(sortKey:{field:CREATED_AT,reverse:true},ids:["a","b"],first:10){`,
+		`orders(filter:{status:$status,tags:[$orders_filter_tags_0,"vip"],range:{from:$from,to:null}}) @include(if:$withOrders){`,
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("missing %q in:\n%s", want, query)
+		}
+	}
+	// 嵌套的变量占位符各自注册为独立的 Variable，路径为所在字段的路径
+	for _, v := range exec.Variables {
+		if v.Name == "$orders_filter_tags_0" && (len(v.Paths) != 1 || v.Paths[0] != "orders") {
+			t.Errorf("unexpected paths for %s: %v", v.Name, v.Paths)
+		}
+	}
+}
+
+func TestCompositeLiteralErrors(t *testing.T) {
+	for _, tag := range []string{
+		`items(filter:{status:ACTIVE)`,
+		`items(filter:{status:ACTIVE,status:DRAFT})`,
+		`items(ids:["a" "b"])`,
+		`items(filter:{status:$s:=1})`,
+	} {
+		typ := reflect.StructOf([]reflect.StructField{{
+			Name: "Items",
+			Type: reflect.TypeOf(struct {
+				ID string `json:"id" graphql:"id"`
+			}{}),
+			Tag: reflect.StructTag("graphql:" + strconv.Quote(tag)),
+		}})
+		if _, err := graphql.Marshal(reflect.New(typ).Elem().Interface()); err == nil {
+			t.Errorf("expected error for tag %s", tag)
+		}
+	}
+}
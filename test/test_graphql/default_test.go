@@ -0,0 +1,100 @@
+package test_graphql
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	graphql "github.com/lascyb/struct-to-graphql"
+)
+
+// 测试输入对象类型的变量默认值
+type ObjectDefaultQuery struct {
+	Orders struct {
+		ID string `json:"id" graphql:"id"`
+	} `json:"orders" graphql:"orders(filter:$filter:OrderFilter={status:ACTIVE,tags:[\"a\"],range:{from:1,to:null}})"`
+}
+
+type DefaultRange struct {
+	From int  `json:"from"`
+	To   *int `json:"to"`
+}
+
+type DefaultFilter struct {
+	Status  graphql.EnumValue `json:"status"`
+	Tags    []string          `json:"tags,omitempty"`
+	Range   *DefaultRange     `graphql:"range"`
+	Note    string            `json:"-"`
+	private string
+}
+
+func TestObjectDefaultValues(t *testing.T) {
+	exec, err := graphql.Marshal(ObjectDefaultQuery{})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	query, err := exec.Query("Orders")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	// tag 中的对象默认值按书写顺序输出
+	if !strings.HasPrefix(query, `query Orders($filter:OrderFilter={status:ACTIVE,tags:["a"],range:{from:1,to:null}}) {`) {
+		t.Errorf("unexpected query:\n%s", query)
+	}
+	// 对象默认值可直接作为变量值发送
+	data, err := exec.Bind(nil)
+	if err != nil {
+		t.Fatalf("Bind failed: %v", err)
+	}
+	if string(data) != `{"filter":{"status":"ACTIVE","tags":["a"],"range":{"from":1,"to":null}}}` {
+		t.Errorf("unexpected variables: %s", data)
+	}
+
+	for _, tc := range []struct {
+		value any
+		want  string
+	}{
+		// map 按键排序
+		{map[string]any{"tags": []any{"b", "a"}, "status": graphql.EnumValue("DRAFT"), "limit": 10}, `{limit:10,status:DRAFT,tags:["b","a"]}`},
+		{map[string]string{"z": "1", "a": "2"}, `{a:"2",z:"1"}`},
+		// 结构体按字段声明顺序，字段名规则与变量结构体相同，omitempty 与 "-" 生效
+		{DefaultFilter{Status: "ACTIVE", Range: &DefaultRange{From: 1}}, `{status:ACTIVE,range:{from:1,to:null}}`},
+		{&DefaultFilter{Status: "ACTIVE", Tags: []string{"x"}}, `{status:ACTIVE,tags:["x"],range:null}`},
+		// 有序的键值对
+		{graphql.ObjectValue{{Name: "b", Value: 1}, {Name: "a", Value: []int{1, 2}}}, `{b:1,a:[1,2]}`},
+		{[]map[string]bool{{"on": true}}, `[{on:true}]`},
+		{(*DefaultFilter)(nil), `null`},
+	} {
+		exec.Variables[0].DefaultValue = tc.value
+		query, err := exec.Query("Orders")
+		if err != nil {
+			t.Fatalf("Query failed: %v", err)
+		}
+		if want := "query Orders($filter:OrderFilter=" + tc.want + ") {"; !strings.HasPrefix(query, want) {
+			t.Errorf("default %#v: got\n%s\nwant prefix %s", tc.value, query, want)
+		}
+	}
+
+	// 键不是字符串的 map 与自定义了 JSON 编码的结构体无法写成输入对象
+	for _, value := range []any{
+		map[int]string{1: "a"},
+		time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		[]any{map[string]any{"at": &time.Time{}}},
+	} {
+		exec.Variables[0].DefaultValue = value
+		_, err := exec.Query("Orders")
+		var fieldErr *graphql.FieldError
+		if !errors.Is(err, graphql.ErrInvalidValue) || !errors.As(err, &fieldErr) || fieldErr.GoPath != "ObjectDefaultQuery.Orders" {
+			t.Errorf("default %#v: expected ErrInvalidValue at ObjectDefaultQuery.Orders, got %v", value, err)
+		}
+	}
+
+	if _, err := graphql.Marshal(struct {
+		Orders struct {
+			ID string `json:"id" graphql:"id"`
+		} `graphql:"orders(filter:$filter:OrderFilter={status:$status})"`
+	}{}); err == nil {
+		t.Error("expected error for variable inside default value")
+	}
+}
@@ -0,0 +1,94 @@
+package test_graphql
+
+import (
+	"strings"
+	"testing"
+
+	graphql "github.com/lascyb/struct-to-graphql"
+)
+
+// 测试输出稳定性：参数按 tag 顺序，变量按首次出现顺序，Fragment 按依赖顺序
+type DeterministicAddress struct {
+	City string `json:"city" graphql:"city"`
+}
+
+type DeterministicProfile struct {
+	Home DeterministicAddress `json:"home" graphql:"home"`
+	Work DeterministicAddress `json:"work" graphql:"work"`
+}
+
+type DeterministicQuery struct {
+	List struct {
+		ID string `json:"id" graphql:"id"`
+	} `json:"list" graphql:"list(query:$:String!,id:$id:Int!,first:10,after:$after:String,last:5)"`
+	Search struct {
+		ID string `json:"id" graphql:"id"`
+	} `json:"search" graphql:"search(zeta:$zeta:String,alpha:$alpha:String,id:$id:Int!)"`
+	Owner    DeterministicProfile `json:"owner" graphql:"owner"`
+	Reviewer DeterministicProfile `json:"reviewer" graphql:"reviewer"`
+}
+
+func TestDeterministicOutput(t *testing.T) {
+	var first string
+	for i := 0; i < 50; i++ {
+		// 禁用缓存，每次都重新生成
+		exec, err := graphql.MarshalWithOptions(DeterministicQuery{}, graphql.Options{DisableCache: true})
+		if err != nil {
+			t.Fatalf("MarshalWithOptions failed: %v", err)
+		}
+		query, err := exec.Query("Deterministic")
+		if err != nil {
+			t.Fatalf("Query failed: %v", err)
+		}
+		if i == 0 {
+			first = query
+			t.Logf("Generated Query:\n%s", query)
+			continue
+		}
+		if query != first {
+			t.Fatalf("output changed between runs:\n%s\n---\n%s", first, query)
+		}
+	}
+}
+
+func TestDeterministicArgumentOrder(t *testing.T) {
+	exec, err := graphql.Marshal(DeterministicQuery{})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if !strings.Contains(exec.Body, "list(query:$list_query,id:$id,first:10,after:$after,last:5)") {
+		t.Errorf("arguments should follow tag order:\n%s", exec.Body)
+	}
+	if !strings.Contains(exec.Body, "search(zeta:$zeta,alpha:$alpha,id:$id)") {
+		t.Errorf("arguments should follow tag order:\n%s", exec.Body)
+	}
+}
+
+func TestDeterministicVariableOrder(t *testing.T) {
+	exec, err := graphql.Marshal(DeterministicQuery{})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var names []string
+	for _, v := range exec.Variables {
+		names = append(names, v.Name)
+	}
+	want := "$list_query,$id,$after,$zeta,$alpha"
+	if got := strings.Join(names, ","); got != want {
+		t.Errorf("got variables %s, want %s", got, want)
+	}
+}
+
+func TestDeterministicFragmentOrder(t *testing.T) {
+	exec, err := graphql.Marshal(DeterministicQuery{})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if len(exec.Fragments) != 2 {
+		t.Fatalf("got %d fragments, want 2", len(exec.Fragments))
+	}
+	// DeterministicProfile 依赖 DeterministicAddress，被依赖者应排在前面
+	if exec.Fragments[0].Type != "DeterministicAddress" || exec.Fragments[1].Type != "DeterministicProfile" {
+		t.Errorf("fragments not in dependency order: %s, %s", exec.Fragments[0].Type, exec.Fragments[1].Type)
+	}
+}
@@ -0,0 +1,145 @@
+package test_graphql
+
+import (
+	"strings"
+	"testing"
+
+	graphql "github.com/lascyb/struct-to-graphql"
+)
+
+// 测试指令（Directives）
+type DirectiveContact struct {
+	Phone string `json:"phone" graphql:"phone"`
+}
+
+type DirectiveStats struct {
+	Followers int `json:"followers" graphql:"followers"`
+}
+
+type DirectiveText struct {
+	Text string `json:"text" graphql:"text"`
+}
+
+type DirectiveImage struct {
+	URL string `json:"url" graphql:"url"`
+}
+
+type DirectiveContent struct {
+	Typename       string `json:"__typename" graphql:"__typename,union"`
+	DirectiveText  `graphql:",@include(if:$withText:Boolean!)"`
+	DirectiveImage `graphql:",@skip(if:$noImage:Boolean=false)"`
+}
+
+type DirectiveUser struct {
+	ID               string `json:"id" graphql:"id"`
+	Email            string `json:"email" graphql:"email,@include(if:$withEmail:Boolean!)"`
+	Name             string `json:"name" graphql:"name,alias=displayName,@cached(ttl:60)"`
+	Avatar           string `json:"avatar" graphql:"avatar(size:64),@skip(if:$:Boolean!)"`
+	DirectiveContact `graphql:"@include(if:$withContact:Boolean!)"`
+}
+
+type DirectiveQuery struct {
+	User    DirectiveUser    `json:"user" graphql:"user(id:$id:ID!),@cached(ttl:30,scope:$scope:String)"`
+	Content DirectiveContent `json:"content" graphql:"content"`
+	DirectiveStats
+	Stats DirectiveStats `json:"stats" graphql:"stats"`
+}
+
+// 复用类型嵌入时输出带指令的片段展开
+type DirectiveSpreadQuery struct {
+	Owner struct {
+		DirectiveStats `graphql:"@include(if:$withStats:Boolean!)"`
+	} `json:"owner" graphql:"owner"`
+	Stats DirectiveStats `json:"stats" graphql:"stats"`
+}
+
+func TestDirectiveOnFields(t *testing.T) {
+	exec, err := graphql.Marshal(DirectiveQuery{})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	query, err := exec.Query("DirectiveTest")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	t.Logf("Generated Query:\n%s", query)
+
+	for _, want := range []string{
+		"email @include(if:$withEmail)",
+		"displayName:name @cached(ttl:60)",
+		"avatar(size:64) @skip(if:$user_avatar_skip_if)",
+		"user(id:$id) @cached(ttl:30,scope:$scope){",
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("missing %q", want)
+		}
+	}
+}
+
+func TestDirectiveOnInlineFragments(t *testing.T) {
+	exec, err := graphql.Marshal(DirectiveQuery{})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	// 联合类型分支
+	if !strings.Contains(exec.Body, "... on DirectiveText @include(if:$withText) {") {
+		t.Errorf("missing directive on union branch:\n%s", exec.Body)
+	}
+	if !strings.Contains(exec.Body, "... on DirectiveImage @skip(if:$noImage) {") {
+		t.Errorf("missing directive on union branch:\n%s", exec.Body)
+	}
+	// 匿名嵌入字段：无类型条件的内联片段
+	if !strings.Contains(exec.Body, "... @include(if:$withContact){") {
+		t.Errorf("missing inline fragment for embedded field with directive:\n%s", exec.Body)
+	}
+	if !strings.Contains(exec.Body, "phone") {
+		t.Errorf("missing phone field:\n%s", exec.Body)
+	}
+}
+
+func TestDirectiveOnFragmentSpread(t *testing.T) {
+	exec, err := graphql.Marshal(DirectiveSpreadQuery{})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	t.Logf("Generated Body:\n%s", exec.Body)
+	if len(exec.Fragments) != 1 {
+		t.Fatalf("got %d fragments, want 1", len(exec.Fragments))
+	}
+	want := "..." + exec.Fragments[0].Name + " @include(if:$withStats)"
+	if !strings.Contains(exec.Body, want) {
+		t.Errorf("missing %q in body:\n%s", want, exec.Body)
+	}
+}
+
+func TestDirectiveVariables(t *testing.T) {
+	exec, err := graphql.Marshal(DirectiveQuery{})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	types := make(map[string]string)
+	for _, v := range exec.Variables {
+		types[v.Name] = v.Type
+	}
+	for name, typ := range map[string]string{
+		"$id":          "ID!",
+		"$scope":       "String",
+		"$withEmail":   "Boolean!",
+		"$withContact": "Boolean!",
+		"$withText":    "Boolean!",
+		"$noImage":     "Boolean",
+
+		"$user_avatar_skip_if": "Boolean!",
+	} {
+		if types[name] != typ {
+			t.Errorf("variable %s: got type %q, want %q", name, types[name], typ)
+		}
+	}
+	query, err := exec.Query("DirectiveTest")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if !strings.Contains(query, "$noImage:Boolean=false") {
+		t.Errorf("missing default value for directive variable:\n%s", query)
+	}
+}
@@ -0,0 +1,91 @@
+package test_graphql
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	graphql "github.com/lascyb/struct-to-graphql"
+)
+
+// 测试枚举字面量与字符串字面量的区分
+type EnumQuery struct {
+	Products struct {
+		ID string `json:"id" graphql:"id"`
+	} `json:"products" graphql:"products(sortKey:enum(TITLE),query:\"TITLE\",status:ACTIVE,sort:$sort:ProductSort=PRICE,locale:$locale:String=en)"`
+	Orders struct {
+		ID string `json:"id" graphql:"id"`
+	} `json:"orders" graphql:"orders(sortKey:$orderSort:OrderSort=enum(CREATED_AT),filter:{status:enum(OPEN),tag:\"OPEN\"}),@cached(scope:enum(PRIVATE))"`
+}
+
+func TestEnumLiterals(t *testing.T) {
+	exec, err := graphql.Marshal(EnumQuery{})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	query, err := exec.Query("Enums")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	for _, want := range []string{
+		// String 类型变量的默认值不带引号时仍为字符串
+		`query Enums($sort:ProductSort=PRICE,$locale:String="en",$orderSort:OrderSort=CREATED_AT) {`,
+		// 顶层不带引号的字面量保持字符串，枚举值需写作 enum(NAME)
+		`products(sortKey:TITLE,query:"TITLE",status:"ACTIVE",sort:$sort,locale:$locale){`,
+		`orders(sortKey:$orderSort,filter:{status:OPEN,tag:"OPEN"}) @cached(scope:PRIVATE){`,
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("missing %q in:\n%s", want, query)
+		}
+	}
+}
+
+// EnumSort 实现 graphql.Enum 的 Go 枚举类型
+type EnumSort int
+
+const (
+	EnumSortTitle EnumSort = iota
+	EnumSortPrice
+)
+
+func (s EnumSort) GraphQLEnum() string {
+	return [...]string{"TITLE", "PRICE"}[s]
+}
+
+func TestEnumInterface(t *testing.T) {
+	type Query struct {
+		Products struct {
+			ID string `json:"id" graphql:"id"`
+		} `json:"products" graphql:"products(sortKey:$sortKey:EnumSort,reverse:$reverse)"`
+	}
+	exec, err := graphql.MarshalWithVars(Query{}, struct {
+		SortKey *EnumSort `json:"sortKey"`
+		Reverse bool
+	}{})
+	if err != nil {
+		t.Fatalf("MarshalWithVars failed: %v", err)
+	}
+	// 实现了 Enum 的默认值按枚举名输出
+	exec.Variables[0].HasDefault, exec.Variables[0].DefaultValue = true, EnumSortPrice
+	query, err := exec.Query("Sorted")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if !strings.HasPrefix(query, "query Sorted($sortKey:EnumSort=PRICE,$reverse:Boolean!) {") {
+		t.Errorf("unexpected query:\n%s", query)
+	}
+
+	exec, err = graphql.MarshalWithVars(Query{}, struct {
+		SortKey EnumSort `json:"sortKey"`
+	}{SortKey: EnumSortPrice})
+	if err != nil {
+		t.Fatalf("MarshalWithVars failed: %v", err)
+	}
+	data, err := json.Marshal(exec.Values)
+	if err != nil {
+		t.Fatalf("marshal values: %v", err)
+	}
+	if string(data) != `{"sortKey":"PRICE"}` {
+		t.Errorf("enum variable should be sent by name, got %s", data)
+	}
+}
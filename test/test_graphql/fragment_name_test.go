@@ -0,0 +1,166 @@
+package test_graphql
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	graphql "github.com/lascyb/struct-to-graphql"
+	"github.com/lascyb/struct-to-graphql/core"
+	amodel "github.com/lascyb/struct-to-graphql/test/test_graphql/internal/a/model"
+	bmodel "github.com/lascyb/struct-to-graphql/test/test_graphql/internal/b/model"
+)
+
+// 测试泛型类型与重名类型的 Fragment 名称
+type FragmentNameProduct struct {
+	ID string `json:"id" graphql:"id"`
+}
+
+type FragmentNameConnection[T any] struct {
+	Nodes    []T `json:"nodes" graphql:"nodes"`
+	PageInfo struct {
+		HasNextPage bool `json:"hasNextPage" graphql:"hasNextPage"`
+	} `json:"pageInfo" graphql:"pageInfo"`
+}
+
+// FragmentNameConnectionFragmentNameProduct 与 FragmentNameConnection[FragmentNameProduct] 的默认 Fragment 名称相同
+type FragmentNameConnectionFragmentNameProduct struct {
+	Total int `json:"total" graphql:"total"`
+}
+
+// 泛型类型没有合法的 GraphQL 类型名，只有带 fragment 标记并用 type= 指定类型条件时才生成 Fragment
+type FragmentNameQuery struct {
+	Products FragmentNameConnection[FragmentNameProduct]                          `json:"products" graphql:"products,fragment,type=ProductConnection"`
+	Featured FragmentNameConnection[FragmentNameProduct]                          `json:"featured" graphql:"featured,fragment,type=ProductConnection"`
+	Stats    FragmentNameConnectionFragmentNameProduct                            `json:"stats" graphql:"stats"`
+	Totals   FragmentNameConnectionFragmentNameProduct                            `json:"totals" graphql:"totals"`
+	Nested   FragmentNameConnection[*FragmentNameConnection[FragmentNameProduct]] `json:"nested" graphql:"nested,fragment,type=ProductConnectionConnection"`
+}
+
+var graphQLName = regexp.MustCompile(`^[_A-Za-z][_0-9A-Za-z]*$`)
+
+func TestFragmentNamesForGenericTypes(t *testing.T) {
+	exec, err := graphql.Marshal(FragmentNameQuery{})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	names := make(map[string]bool)
+	for _, fragment := range exec.Fragments {
+		if !graphQLName.MatchString(fragment.Name) || !graphQLName.MatchString(fragment.Type) {
+			t.Errorf("invalid fragment name or type condition: %s on %s", fragment.Name, fragment.Type)
+		}
+		if names[fragment.Name] {
+			t.Errorf("duplicate fragment name %s", fragment.Name)
+		}
+		names[fragment.Name] = true
+	}
+	query, err := exec.Query("Generic")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	for _, want := range []string{
+		// 重名时按类型全名排序，与字段顺序无关
+		"fragment Test_graphqlFragmentNameConnectionFragmentNameProduct on FragmentNameConnectionFragmentNameProduct{",
+		"fragment Test_graphqlFragmentNameConnectionFragmentNameProduct2 on ProductConnection{",
+		"fragment Test_graphqlFragmentNameConnectionFragmentNameConnectionFragmentNameProduct on ProductConnectionConnection{",
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("missing %q in:\n%s", want, query)
+		}
+	}
+}
+
+func TestFragmentGenericTypeCondition(t *testing.T) {
+	// 未指定 type= 的泛型类型即使被多次引用也直接展开，其中的命名结构体照常生成 Fragment
+	exec, err := graphql.Marshal(struct {
+		Products FragmentNameConnection[FragmentNameProduct] `json:"products" graphql:"products"`
+		Featured FragmentNameConnection[FragmentNameProduct] `json:"featured" graphql:"featured"`
+	}{})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if len(exec.Fragments) != 1 || exec.Fragments[0].Type != "FragmentNameProduct" {
+		t.Errorf("expected only the FragmentNameProduct fragment, got %v", exec.Fragments)
+	}
+
+	// fragment 标记需要 type= 指定类型条件
+	_, err = graphql.Marshal(struct {
+		Products FragmentNameConnection[FragmentNameProduct] `json:"products" graphql:"products,fragment"`
+	}{})
+	if !errors.Is(err, graphql.ErrInvalidTag) || !strings.Contains(err.Error(), "type=") {
+		t.Errorf("expected an error asking for type=, got %v", err)
+	}
+}
+
+// 不同包中的同名类型：调整字段顺序不改变 Fragment 名称
+type FragmentNameSamePackageAB struct {
+	A1 amodel.Product `json:"a1" graphql:"a1"`
+	A2 amodel.Product `json:"a2" graphql:"a2"`
+	B1 bmodel.Product `json:"b1" graphql:"b1"`
+	B2 bmodel.Product `json:"b2" graphql:"b2"`
+}
+
+type FragmentNameSamePackageBA struct {
+	B1 bmodel.Product `json:"b1" graphql:"b1"`
+	B2 bmodel.Product `json:"b2" graphql:"b2"`
+	A1 amodel.Product `json:"a1" graphql:"a1"`
+	A2 amodel.Product `json:"a2" graphql:"a2"`
+}
+
+func TestFragmentNamesForSameNamedTypes(t *testing.T) {
+	for _, v := range []any{FragmentNameSamePackageAB{}, FragmentNameSamePackageBA{}} {
+		exec, err := graphql.Marshal(v)
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+		// 包路径靠前的 a/model 使用不带后缀的名称
+		for _, want := range []string{"a1{ ...ModelProduct }", "a2{ ...ModelProduct }", "b1{ ...ModelProduct2 }", "b2{ ...ModelProduct2 }"} {
+			if !strings.Contains(exec.Body, want) {
+				t.Errorf("%T: missing %q in:\n%s", v, want, exec.Body)
+			}
+		}
+	}
+}
+
+func TestFragmentNamer(t *testing.T) {
+	exec, err := graphql.MarshalWithOptions(FragmentNameQuery{}, graphql.Options{
+		FragmentNamer: func(typ reflect.Type) string {
+			switch typ {
+			case reflect.TypeFor[FragmentNameConnectionFragmentNameProduct]():
+				return "" // 使用默认名称
+			case reflect.TypeFor[FragmentNameProduct]():
+				return "ProductParts"
+			}
+			return "Connection-Parts" // 非法字符被去除，重名时追加后缀
+		},
+	})
+	if err != nil {
+		t.Fatalf("MarshalWithOptions failed: %v", err)
+	}
+	var got []string
+	for _, fragment := range exec.Fragments {
+		got = append(got, fragment.Name)
+	}
+	// 重名时按类型全名排序分配后缀：FragmentNameConnection[*...] 排在 FragmentNameConnection[github.com/...] 之前
+	want := "ProductParts,ConnectionParts2,Test_graphqlFragmentNameConnectionFragmentNameProduct,ConnectionParts"
+	if strings.Join(got, ",") != want {
+		t.Errorf("got fragment names %v, want %s", got, want)
+	}
+	if !strings.Contains(exec.Body, "products{ ...ConnectionParts2 }") || !strings.Contains(exec.Body, "nested{ ...ConnectionParts }") {
+		t.Errorf("unexpected body:\n%s", exec.Body)
+	}
+}
+
+func TestDefaultFragmentName(t *testing.T) {
+	for typ, want := range map[reflect.Type]string{
+		reflect.TypeFor[UserInfo]():                                       "Test_graphqlUserInfo",
+		reflect.TypeFor[FragmentNameConnection[FragmentNameProduct]]():    "Test_graphqlFragmentNameConnectionFragmentNameProduct",
+		reflect.TypeFor[FragmentNameConnection[map[string][]*UserInfo]](): "Test_graphqlFragmentNameConnectionMapStringUserInfo",
+	} {
+		if got := core.DefaultFragmentName(typ); got != want {
+			t.Errorf("DefaultFragmentName(%v) = %q, want %q", typ, got, want)
+		}
+	}
+}
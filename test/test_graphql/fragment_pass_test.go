@@ -0,0 +1,144 @@
+package test_graphql
+
+import (
+	"testing"
+
+	graphql "github.com/lascyb/struct-to-graphql"
+)
+
+// 测试联合类型分支、平铺的匿名嵌入字段与嵌套 Fragment 中被复用的类型
+type FragmentPassMoney struct {
+	Amount string `json:"amount" graphql:"amount"`
+}
+
+type FragmentPassProduct struct {
+	ID    string            `json:"id" graphql:"id"`
+	Price FragmentPassMoney `json:"price" graphql:"price"`
+}
+
+type FragmentPassArticle struct {
+	Title string `json:"title" graphql:"title"`
+}
+
+type FragmentPassResult struct {
+	Typename string `json:"__typename" graphql:"__typename,union"`
+	FragmentPassProduct
+	FragmentPassArticle
+}
+
+type FragmentPassQuery struct {
+	Line struct {
+		FragmentPassMoney
+		Qty int `json:"qty" graphql:"qty"`
+	} `json:"line" graphql:"line"`
+	Total   FragmentPassMoney    `json:"total" graphql:"total"`
+	Search  []FragmentPassResult `json:"search" graphql:"search"`
+	Again   []FragmentPassResult `json:"again" graphql:"again"`
+	Product FragmentPassProduct  `json:"product" graphql:"product"`
+}
+
+func TestFragmentPass(t *testing.T) {
+	exec, err := graphql.Marshal(FragmentPassQuery{})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	query, err := exec.Query("FragmentPass")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	want := `fragment Test_graphqlFragmentPassMoney on FragmentPassMoney{
+  amount
+}
+fragment Test_graphqlFragmentPassProduct on FragmentPassProduct{
+  id
+  price{ ...Test_graphqlFragmentPassMoney }
+}
+fragment Test_graphqlFragmentPassResult on FragmentPassResult{
+  __typename
+  ... on FragmentPassProduct { ...Test_graphqlFragmentPassProduct }
+  ... on FragmentPassArticle {
+    title
+  }
+}
+query FragmentPass {
+  line{
+    ...Test_graphqlFragmentPassMoney
+    qty
+  }
+  total{ ...Test_graphqlFragmentPassMoney }
+  search{ ...Test_graphqlFragmentPassResult }
+  again{ ...Test_graphqlFragmentPassResult }
+  product{ ...Test_graphqlFragmentPassProduct }
+}`
+	if query != want {
+		t.Errorf("got:\n%s\nwant:\n%s", query, want)
+	}
+}
+
+// type= 改写了类型条件的引用不与自动生成的 Fragment 共用
+type FragmentPassTypeQuery struct {
+	Price FragmentPassMoney `json:"price" graphql:"price,type=Price"`
+	Total FragmentPassMoney `json:"total" graphql:"total"`
+	Tax   FragmentPassMoney `json:"tax" graphql:"tax"`
+	Fee   FragmentPassMoney `json:"fee" graphql:"fee,type=Price"`
+}
+
+func TestFragmentPassTypeCondition(t *testing.T) {
+	exec, err := graphql.Marshal(FragmentPassTypeQuery{})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	want := `{
+  price{
+    amount
+  }
+  total{ ...Test_graphqlFragmentPassMoney }
+  tax{ ...Test_graphqlFragmentPassMoney }
+  fee{
+    amount
+  }
+}`
+	if exec.Body != want {
+		t.Errorf("got:\n%s\nwant:\n%s", exec.Body, want)
+	}
+	if len(exec.Fragments) != 1 || exec.Fragments[0].Type != "FragmentPassMoney" {
+		t.Errorf("got fragments %+v", exec.Fragments)
+	}
+}
+
+// 父级在每一处引用都直接展开时，其中的类型按每一处引用计数
+type FragmentPassLine struct {
+	Money FragmentPassMoney `json:"m" graphql:"m"`
+}
+
+func TestFragmentPassInlinedParents(t *testing.T) {
+	type NoFragmentQuery struct {
+		A FragmentPassLine `json:"a" graphql:"a,nofragment"`
+		B FragmentPassLine `json:"b" graphql:"b,nofragment"`
+	}
+	// 别名：两个字段的类型是同一个匿名结构体
+	type Line = struct {
+		Money FragmentPassMoney `json:"m" graphql:"m"`
+	}
+	type AnonymousQuery struct {
+		A Line `json:"a" graphql:"a"`
+		B Line `json:"b" graphql:"b"`
+	}
+	want := `{
+  a{
+    m{ ...Test_graphqlFragmentPassMoney }
+  }
+  b{
+    m{ ...Test_graphqlFragmentPassMoney }
+  }
+}`
+	for _, query := range []any{NoFragmentQuery{}, AnonymousQuery{}} {
+		exec, err := graphql.Marshal(query)
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+		if exec.Body != want || len(exec.Fragments) != 1 {
+			t.Errorf("%T: got %d fragments, body:\n%s\nwant:\n%s", query, len(exec.Fragments), exec.Body, want)
+		}
+	}
+}
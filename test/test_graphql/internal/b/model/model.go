@@ -0,0 +1,6 @@
+// Package model 与 a/model 同名，用于测试不同包中同名类型的 Fragment 名称
+package model
+
+type Product struct {
+	SKU string `json:"sku" graphql:"sku"`
+}
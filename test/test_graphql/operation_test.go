@@ -0,0 +1,62 @@
+package test_graphql
+
+import (
+	"testing"
+
+	graphql "github.com/lascyb/struct-to-graphql"
+)
+
+// 测试按类型参数生成查询并解码为同一类型
+type OperationProduct struct {
+	ID    string `json:"id" graphql:"id"`
+	Title string `json:"title" graphql:"title,alias=name"`
+}
+
+type OperationQuery struct {
+	Product OperationProduct `json:"product" graphql:"product(id:$id:ID!)"`
+}
+
+func TestMarshalType(t *testing.T) {
+	op, err := graphql.MarshalType[OperationQuery]()
+	if err != nil {
+		t.Fatalf("MarshalType failed: %v", err)
+	}
+	exec, err := graphql.Marshal(OperationQuery{})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	got, err := op.Query("Product")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	want, _ := exec.Query("Product")
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	value, err := op.Decode([]byte(`{"product":{"id":"1","name":"Shirt"}}`))
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if value.Product.ID != "1" || value.Product.Title != "Shirt" {
+		t.Errorf("got %+v", value)
+	}
+}
+
+func TestMarshalTypePointer(t *testing.T) {
+	op, err := graphql.MarshalTypeWithOptions[*OperationQuery](graphql.Options{Indent: "\t"})
+	if err != nil {
+		t.Fatalf("MarshalTypeWithOptions failed: %v", err)
+	}
+	value, err := op.Decode([]byte(`{"product":{"id":"2","name":"Hat"}}`))
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if value == nil || value.Product.Title != "Hat" {
+		t.Errorf("got %+v", value)
+	}
+
+	if _, err := graphql.MarshalType[string](); err == nil {
+		t.Error("expected error for non-struct type parameter")
+	}
+}
@@ -0,0 +1,131 @@
+package test_graphql
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	graphql "github.com/lascyb/struct-to-graphql"
+)
+
+// 测试单次调用选项
+type OptionsQuery struct {
+	Items struct {
+		Nodes []struct {
+			ID string `json:"id" graphql:"id"`
+		} `json:"nodes" graphql:"nodes"`
+	} `json:"items" graphql:"items(first:$:Int!)"`
+}
+
+func TestOptionsIndent(t *testing.T) {
+	exec, err := graphql.MarshalWithOptions(OptionsQuery{}, graphql.Options{Indent: "\t"})
+	if err != nil {
+		t.Fatalf("MarshalWithOptions failed: %v", err)
+	}
+	want := "{\n\titems(first:$items_first){\n\t\tnodes{\n\t\t\tid\n\t\t}\n\t}\n}"
+	if exec.Body != want {
+		t.Errorf("got body:\n%s\nwant:\n%s", exec.Body, want)
+	}
+
+	// 默认选项不受其他调用影响
+	exec, err = graphql.Marshal(OptionsQuery{})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if !strings.Contains(exec.Body, "\n  items(") {
+		t.Errorf("default indent should be two spaces:\n%s", exec.Body)
+	}
+}
+
+func TestOptionsVariableNamer(t *testing.T) {
+	exec, err := graphql.MarshalWithOptions(OptionsQuery{}, graphql.Options{
+		VariableNamer: func(paths []string, arg string) string {
+			return strings.Join(append(paths, arg), "__")
+		},
+	})
+	if err != nil {
+		t.Fatalf("MarshalWithOptions failed: %v", err)
+	}
+	if !strings.Contains(exec.Body, "items(first:$items__first)") {
+		t.Errorf("custom variable name not applied:\n%s", exec.Body)
+	}
+	if len(exec.Variables) != 1 || exec.Variables[0].Name != "$items__first" {
+		t.Errorf("unexpected variables: %+v", exec.Variables)
+	}
+}
+
+// 并发使用不同缩进互不干扰
+func TestOptionsConcurrentIndent(t *testing.T) {
+	indents := []string{" ", "  ", "    ", "\t"}
+	var wg sync.WaitGroup
+	errs := make(chan error, len(indents)*50)
+	for _, indent := range indents {
+		for range 50 {
+			wg.Add(1)
+			go func(indent string) {
+				defer wg.Done()
+				exec, err := graphql.MarshalWithOptions(OptionsQuery{}, graphql.Options{Indent: indent})
+				if err != nil {
+					errs <- err
+					return
+				}
+				if !strings.Contains(exec.Body, "\n"+indent+indent+indent+"id\n") {
+					errs <- fmt.Errorf("indent %q not applied:\n%s", indent, exec.Body)
+				}
+			}(indent)
+		}
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestOptionsNoIndent(t *testing.T) {
+	exec, err := graphql.MarshalWithOptions(OptionsQuery{}, graphql.Options{Indent: "\t", NoIndent: true})
+	if err != nil {
+		t.Fatalf("MarshalWithOptions failed: %v", err)
+	}
+	want := "{\nitems(first:$items_first){\nnodes{\nid\n}\n}\n}"
+	if exec.Body != want {
+		t.Errorf("got body:\n%s\nwant:\n%s", exec.Body, want)
+	}
+}
+
+// SetIndent 只影响不接受选项的 Marshal、MarshalType、MarshalWithVars，接受选项的调用未设置 Indent 时始终为两个空格
+func TestOptionsIgnoreSetIndent(t *testing.T) {
+	graphql.SetIndent("\t")
+	t.Cleanup(func() { graphql.SetIndent("  ") })
+	exec, err := graphql.MarshalWithOptions(OptionsQuery{}, graphql.Options{})
+	if err != nil {
+		t.Fatalf("MarshalWithOptions failed: %v", err)
+	}
+	if !strings.Contains(exec.Body, "\n  items(") {
+		t.Errorf("SetIndent should not affect MarshalWithOptions:\n%s", exec.Body)
+	}
+	exec, err = graphql.Marshal(OptionsQuery{})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if !strings.Contains(exec.Body, "\n\titems(") {
+		t.Errorf("Marshal should use the SetIndent value:\n%s", exec.Body)
+	}
+	op, err := graphql.MarshalType[OptionsQuery]()
+	if err != nil {
+		t.Fatalf("MarshalType failed: %v", err)
+	}
+	if op.Body != exec.Body {
+		t.Errorf("MarshalType should indent the same as Marshal:\n%s\nwant:\n%s", op.Body, exec.Body)
+	}
+	withVars, err := graphql.MarshalWithVars(OptionsQuery{}, struct {
+		ItemsFirst int `json:"items_first"`
+	}{ItemsFirst: 10})
+	if err != nil {
+		t.Fatalf("MarshalWithVars failed: %v", err)
+	}
+	if withVars.Body != exec.Body {
+		t.Errorf("MarshalWithVars should indent the same as Marshal:\n%s\nwant:\n%s", withVars.Body, exec.Body)
+	}
+}
@@ -0,0 +1,123 @@
+package test_graphql
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	graphql "github.com/lascyb/struct-to-graphql"
+	"github.com/lascyb/struct-to-graphql/core"
+	"github.com/lascyb/struct-to-graphql/internal/lexer"
+)
+
+// 测试字符串字面量与默认值的转义，输出经 GraphQL 词法分析后应得到原始值
+var stringCases = []string{
+	"",
+	`say "hi"`,
+	`C:\path\to`,
+	"line1\nline2",
+	"tab\tcr\rcrlf\r\n",
+	"nul\x00unit\x1fdel\x7f",
+	"é 中文 \u2028",
+	"emoji 😀 𝄞",
+	`triple """ quotes`,
+}
+
+func TestQuoteStringRoundTrip(t *testing.T) {
+	for _, s := range stringCases {
+		quoted := core.QuoteString(s)
+		tokens, err := lexer.Tokenize(quoted)
+		if err != nil || len(tokens) != 1 || tokens[0].Kind != lexer.String {
+			t.Errorf("QuoteString(%q) = %s is not a single string token: %v", s, quoted, err)
+			continue
+		}
+		if tokens[0].Value != s {
+			t.Errorf("round trip of %q: got %q via %s", s, tokens[0].Value, quoted)
+		}
+	}
+	if got := core.QuoteString("😀\xff"); got != `"\uD83D\uDE00`+"\uFFFD"+`"` {
+		t.Errorf("unexpected escaping of non-BMP and invalid UTF-8: %s", got)
+	}
+}
+
+func TestBlockStringRoundTrip(t *testing.T) {
+	printable := []string{
+		"",
+		"single line",
+		"line1\nline2",
+		"  indented first\nsecond",
+		"first\n  indented\n    more",
+		`ends with "`,
+		`ends with \`,
+		"contains \"\"\" quotes\nand \\\"\"\" escaped",
+		"tabs\tinside\n\tand indent",
+	}
+	for _, s := range printable {
+		block, ok := core.BlockString(s)
+		if !ok {
+			t.Errorf("BlockString(%q) should be printable", s)
+			continue
+		}
+		tokens, err := lexer.Tokenize(block)
+		if err != nil || len(tokens) != 1 || tokens[0].Kind != lexer.BlockString {
+			t.Errorf("BlockString(%q) = %s is not a single block string token: %v", s, block, err)
+			continue
+		}
+		if tokens[0].Value != s {
+			t.Errorf("round trip of %q: got %q via %s", s, tokens[0].Value, block)
+		}
+	}
+	// 块字符串的取值规则会改写这些内容，只能输出为普通字符串
+	for _, s := range []string{"\nleading blank line", "trailing blank line\n", "  common\n  indent", "crlf\r\nline", "nul\x00"} {
+		if block, ok := core.BlockString(s); ok {
+			t.Errorf("BlockString(%q) should not be printable, got %s", s, block)
+		}
+	}
+}
+
+func TestStringLiteralEscaping(t *testing.T) {
+	// tag 中的双引号字符串按 GraphQL 规范处理转义
+	tag := `search(query:"line1\nsay \"hi\" C:\\dir \u00E9")`
+	typ := reflect.StructOf([]reflect.StructField{{
+		Name: "Search",
+		Type: reflect.TypeOf(struct {
+			ID string `json:"id" graphql:"id"`
+		}{}),
+		Tag: reflect.StructTag("graphql:" + strconv.Quote(tag)),
+	}})
+	want := "line1\nsay \"hi\" C:\\dir é"
+
+	for _, block := range []bool{false, true} {
+		exec, err := graphql.MarshalWithOptions(reflect.New(typ).Elem().Interface(), graphql.Options{BlockStrings: block})
+		if err != nil {
+			t.Fatalf("MarshalWithOptions failed: %v", err)
+		}
+		exec.Variables = append(exec.Variables, &core.Variable{
+			Name: "$note", Type: "String", HasDefault: true, DefaultValue: "tab\t😀",
+		})
+		query, err := exec.Query("Search")
+		if err != nil {
+			t.Fatalf("Query failed: %v", err)
+		}
+		if !strings.HasPrefix(query, `query Search($note:String="tab\t\uD83D\uDE00") {`) {
+			t.Errorf("unexpected variable default:\n%s", query)
+		}
+		if block != strings.Contains(query, `"""`) {
+			t.Errorf("BlockStrings=%v, got query:\n%s", block, query)
+		}
+		tokens, err := lexer.Tokenize(query)
+		if err != nil {
+			t.Fatalf("generated query is not lexically valid: %v\n%s", err, query)
+		}
+		var values []string
+		for _, tok := range tokens {
+			if tok.Kind == lexer.String || tok.Kind == lexer.BlockString {
+				values = append(values, tok.Value)
+			}
+		}
+		if len(values) != 2 || values[0] != "tab\t😀" || values[1] != want {
+			t.Errorf("BlockStrings=%v: got string values %q", block, values)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package test_graphql
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	graphql "github.com/lascyb/struct-to-graphql"
+)
+
+// 测试由变量结构体推导变量类型与取值
+type VarsSortKeys string
+
+// GraphQLEnum 实现 graphql.Enum，变量类型使用 Go 类型名
+func (k VarsSortKeys) GraphQLEnum() string {
+	return string(k)
+}
+
+type VarsCount int
+
+type VarsProductInput struct {
+	Title string   `json:"title"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+type VarsQuery struct {
+	Products struct {
+		Nodes []struct {
+			ID string `json:"id" graphql:"id"`
+		} `json:"nodes" graphql:"nodes"`
+	} `json:"products" graphql:"products(first:$first,after:$after,sortKey:$sortKey,ids:$ids)"`
+	Preview struct {
+		ID string `json:"id" graphql:"id"`
+	} `json:"preview" graphql:"productPreview(input:$input,id:$id:ID!)"`
+}
+
+type VarsValues struct {
+	First   int
+	After   *string
+	SortKey VarsSortKeys `json:"sortKey"`
+	IDs     []string     `graphql:"ids,type=[ID!]"`
+	Input   VarsProductInput
+	ID      string `graphql:"id"`
+}
+
+func TestMarshalWithVars(t *testing.T) {
+	cursor := "abc"
+	exec, err := graphql.MarshalWithVars(VarsQuery{}, VarsValues{
+		First:   10,
+		After:   &cursor,
+		SortKey: "TITLE",
+		IDs:     []string{"1", "2"},
+		Input:   VarsProductInput{Title: "Shirt"},
+		ID:      "gid://1",
+	})
+	if err != nil {
+		t.Fatalf("MarshalWithVars failed: %v", err)
+	}
+	query, err := exec.Query("Products")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	// tag 中显式声明的 ID! 优先于由 string 推导的 String!
+	want := "query Products($first:Int!,$after:String,$sortKey:VarsSortKeys!,$ids:[ID!],$input:VarsProductInput!,$id:ID!) {"
+	if !strings.HasPrefix(query, want) {
+		t.Errorf("got query:\n%s\nwant prefix:\n%s", query, want)
+	}
+
+	data, err := json.Marshal(exec.Values)
+	if err != nil {
+		t.Fatalf("marshal values: %v", err)
+	}
+	wantValues := `{"after":"abc","first":10,"id":"gid://1","ids":["1","2"],"input":{"title":"Shirt"},"sortKey":"TITLE"}`
+	if string(data) != wantValues {
+		t.Errorf("got values %s, want %s", data, wantValues)
+	}
+}
+
+func TestMarshalWithVarsNilAndLists(t *testing.T) {
+	type Query struct {
+		Items []struct {
+			ID string `json:"id" graphql:"id"`
+		} `json:"items" graphql:"items(after:$after,ids:$ids,matrix:$matrix,count:$count)"`
+	}
+	// 非结构体的命名类型按底层类型推导
+	exec, err := graphql.MarshalWithVars(Query{}, &struct {
+		After  *string
+		IDs    []int64 `json:"ids"`
+		Matrix [][]*float64
+		Count  VarsCount
+	}{})
+	if err != nil {
+		t.Fatalf("MarshalWithVars failed: %v", err)
+	}
+	types := make(map[string]string)
+	for _, v := range exec.Variables {
+		types[v.Name] = v.Type
+	}
+	for name, typ := range map[string]string{"$after": "String", "$ids": "[Int!]", "$matrix": "[[Float]]", "$count": "Int!"} {
+		if types[name] != typ {
+			t.Errorf("variable %s: got type %q, want %q", name, types[name], typ)
+		}
+	}
+	if v, ok := exec.Values["after"]; !ok || v != nil {
+		t.Errorf("nil pointer should be sent as null, got %v", v)
+	}
+}
+
+func TestMarshalWithVarsErrors(t *testing.T) {
+	if _, err := graphql.MarshalWithVars(VarsQuery{}, struct{ Unused int }{}); !errors.Is(err, graphql.ErrUnusedVariable) {
+		t.Errorf("expected error for unused variable, got %v", err)
+	}
+	if _, err := graphql.MarshalWithVars(VarsQuery{}, struct{ First map[string]any }{}); err == nil {
+		t.Error("expected error for unsupported Go type")
+	}
+	// 自定义了 JSON 编码的类型无法推导，需要用 type= 指定
+	for _, vars := range []any{struct{ First time.Time }{}, struct{ First json.RawMessage }{}} {
+		if _, err := graphql.MarshalWithVars(VarsQuery{}, vars); !errors.Is(err, graphql.ErrInvalidVariables) || !strings.Contains(err.Error(), "type=") {
+			t.Errorf("%T: expected an error asking for type=, got %v", vars, err)
+		}
+	}
+	if _, err := graphql.MarshalWithVars(VarsQuery{}, struct {
+		First time.Time `graphql:"first,type=DateTime!"`
+	}{}); err != nil {
+		t.Errorf("type= should override the Go type: %v", err)
+	}
+	if _, err := graphql.MarshalWithVars(VarsQuery{}, 1); err == nil {
+		t.Error("expected error for non-struct variables")
+	}
+}
+
+type VarsConflictValues struct {
+	First int
+	IDs   []*string `graphql:"ids"`
+}
+
+func TestMarshalWithVarsTypeConflict(t *testing.T) {
+	type Query struct {
+		Products struct {
+			ID string `json:"id" graphql:"id"`
+		} `json:"products" graphql:"products(first:$first:String!,ids:$ids:[ID!])"`
+	}
+	// tag 中显式声明的类型与 Go 类型不兼容：int 不能作为 String!，元素可为 nil 的切片不能作为 [ID!]
+	_, err := graphql.MarshalWithVarsOptions(Query{}, VarsConflictValues{}, graphql.Options{AllErrors: true})
+	if !errors.Is(err, graphql.ErrVariableTypeConflict) {
+		t.Fatalf("expected type conflict, got %v", err)
+	}
+	var paths []string
+	for _, fieldErr := range graphql.FieldErrors(err) {
+		paths = append(paths, fieldErr.GoPath)
+	}
+	if want := []string{"VarsConflictValues.First", "VarsConflictValues.IDs"}; strings.Join(paths, ",") != strings.Join(want, ",") {
+		t.Errorf("got error paths %v, want %v", paths, want)
+	}
+
+	// 非空的 Go 类型可以传给可空变量，ID 接收 String 与 Int
+	if _, err := graphql.MarshalWithVars(Query{}, struct {
+		First string
+		IDs   []int `graphql:"ids"`
+	}{}); err != nil {
+		t.Errorf("compatible types reported as conflict: %v", err)
+	}
+}
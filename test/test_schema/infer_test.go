@@ -0,0 +1,151 @@
+package test_schema
+
+import (
+	"strings"
+	"testing"
+
+	graphql "github.com/lascyb/struct-to-graphql"
+	"github.com/lascyb/struct-to-graphql/schema"
+)
+
+// 测试按 schema 推断变量类型
+type InferImage struct {
+	URL string `json:"url" graphql:"url(width:$width,height:$)"`
+}
+
+type InferSearchProduct struct {
+	Tags []string `graphql:"tags(first:$tagCount)"`
+}
+
+type InferSearchResult struct {
+	Typename           string `graphql:"__typename,union"`
+	InferSearchProduct `graphql:",type=Product"`
+}
+
+type InferQuery struct {
+	Product struct {
+		Title  string       `json:"title" graphql:"title,@include(if:$withTitle)"`
+		Images []InferImage `json:"images" graphql:"images(first:$first:Int!,sortKey:$sortKey)"`
+	} `json:"product" graphql:"product(id:$id),alias=item"`
+	Search []InferSearchResult `json:"search" graphql:"search(query:$query)"`
+	Node   struct {
+		ID string `json:"id" graphql:"id"`
+	} `json:"node" graphql:"node(id:$id)"`
+}
+
+func TestInferVariableTypes(t *testing.T) {
+	s, err := schema.Parse(shopSDL)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	exec, err := graphql.MarshalWithOptions(InferQuery{}, graphql.Options{Schema: s})
+	if err != nil {
+		t.Fatalf("MarshalWithOptions failed: %v", err)
+	}
+	types := make(map[string]string)
+	for _, v := range exec.Variables {
+		types[v.Name] = v.Type
+	}
+	for name, typ := range map[string]string{
+		"$id":        "ID!",
+		"$withTitle": "Boolean!",
+		"$first":     "Int!", // 显式声明的类型优先于 schema 中的 Int
+		"$sortKey":   "ProductSortKeys",
+		"$width":     "Int",
+		"$query":     "String!",
+		"$tagCount":  "Int!",
+
+		"$item_product_images_url_height": "Int",
+	} {
+		if types[name] != typ {
+			t.Errorf("variable %s: got type %q, want %q", name, types[name], typ)
+		}
+	}
+	query, err := exec.Query("InferQuery")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if !strings.HasPrefix(query, "query InferQuery($id:ID!,$withTitle:Boolean!,$first:Int!,$sortKey:ProductSortKeys,") {
+		t.Errorf("unexpected query:\n%s", query)
+	}
+	if err := s.Validate(exec, "query"); err != nil {
+		t.Errorf("inferred query should validate:\n%v", err)
+	}
+}
+
+type InferMutation struct {
+	ProductUpdate struct {
+		ID string `json:"id" graphql:"id"`
+	} `json:"productUpdate" graphql:"productUpdate(input:$input)"`
+}
+
+func TestInferVariableTypesOperation(t *testing.T) {
+	s, err := schema.Parse(shopSDL)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	// 未指定 Operation 时顶层字段按 query、mutation、subscription 根类型查找
+	exec, err := graphql.MarshalWithOptions(InferMutation{}, graphql.Options{Schema: s})
+	if err != nil {
+		t.Fatalf("MarshalWithOptions failed: %v", err)
+	}
+	mutation, err := exec.Mutation("Update")
+	if err != nil {
+		t.Fatalf("Mutation failed: %v", err)
+	}
+	if !strings.HasPrefix(mutation, "mutation Update($input:ProductInput!) {") {
+		t.Errorf("unexpected mutation:\n%s", mutation)
+	}
+
+	// 指定 Operation 后只在对应根类型中查找
+	exec, err = graphql.MarshalWithOptions(InferMutation{}, graphql.Options{Schema: s, Operation: "query"})
+	if err != nil {
+		t.Fatalf("MarshalWithOptions failed: %v", err)
+	}
+	if _, err := exec.Query("Update"); err == nil || !strings.Contains(err.Error(), "$input") {
+		t.Errorf("expected missing type error for $input, got %v", err)
+	}
+}
+
+func TestInferWithNilSchema(t *testing.T) {
+	// 值为 nil 的 *schema.Schema 按空 schema 处理：不推断类型，也不会 panic
+	var s *schema.Schema
+	exec, err := graphql.MarshalWithOptions(InferMutation{}, graphql.Options{Schema: s, Operation: "mutation"})
+	if err != nil {
+		t.Fatalf("MarshalWithOptions failed: %v", err)
+	}
+	if len(exec.Variables) != 1 || exec.Variables[0].Type != "" {
+		t.Errorf("expected $input without a type, got %+v", exec.Variables)
+	}
+	if err := s.Validate(exec, "mutation"); err == nil {
+		t.Error("expected error for validating against a nil schema")
+	}
+}
+
+type InferCompositeMutation struct {
+	ProductUpdate struct {
+		ID string `json:"id" graphql:"id"`
+	} `json:"productUpdate" graphql:"productUpdate(input:{title:$title,tags:[\"sale\",$]})"`
+}
+
+func TestInferCompositeVariableTypes(t *testing.T) {
+	s, err := schema.Parse(shopSDL)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	exec, err := graphql.MarshalWithOptions(InferCompositeMutation{}, graphql.Options{Schema: s, Operation: "mutation"})
+	if err != nil {
+		t.Fatalf("MarshalWithOptions failed: %v", err)
+	}
+	mutation, err := exec.Mutation("Update")
+	if err != nil {
+		t.Fatalf("Mutation failed: %v", err)
+	}
+	// 复合字面量中的变量按输入对象字段与列表元素的类型推断
+	if !strings.HasPrefix(mutation, "mutation Update($title:String!,$product_update_input_tags_1:String!) {") {
+		t.Errorf("unexpected mutation:\n%s", mutation)
+	}
+	if err := s.Validate(exec, "mutation"); err != nil {
+		t.Errorf("inferred mutation should validate:\n%v", err)
+	}
+}
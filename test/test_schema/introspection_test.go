@@ -0,0 +1,133 @@
+package test_schema
+
+import (
+	"strings"
+	"testing"
+
+	graphql "github.com/lascyb/struct-to-graphql"
+	"github.com/lascyb/struct-to-graphql/internal/lexer"
+	"github.com/lascyb/struct-to-graphql/schema"
+)
+
+// 测试内省结果加载与内省查询生成
+const userIntrospection = `{
+  "data": {
+    "__schema": {
+      "queryType": {"name": "Query"},
+      "mutationType": null,
+      "subscriptionType": null,
+      "types": [
+        {"kind": "OBJECT", "name": "Query", "fields": [
+          {"name": "user", "args": [
+            {"name": "id", "type": {"kind": "NON_NULL", "name": null, "ofType": {"kind": "SCALAR", "name": "ID", "ofType": null}}, "defaultValue": null}
+          ], "type": {"kind": "OBJECT", "name": "User", "ofType": null}},
+          {"name": "users", "args": [
+            {"name": "first", "type": {"kind": "SCALAR", "name": "Int", "ofType": null}, "defaultValue": "10"},
+            {"name": "role", "type": {"kind": "ENUM", "name": "Role", "ofType": null}, "defaultValue": null}
+          ], "type": {"kind": "NON_NULL", "name": null, "ofType": {"kind": "LIST", "name": null, "ofType": {"kind": "NON_NULL", "name": null, "ofType": {"kind": "OBJECT", "name": "User", "ofType": null}}}}}
+        ], "interfaces": [], "inputFields": null, "enumValues": null, "possibleTypes": null},
+        {"kind": "OBJECT", "name": "User", "description": "A user", "fields": [
+          {"name": "id", "args": [], "type": {"kind": "NON_NULL", "name": null, "ofType": {"kind": "SCALAR", "name": "ID", "ofType": null}}},
+          {"name": "name", "args": [], "type": {"kind": "SCALAR", "name": "String", "ofType": null}}
+        ], "interfaces": [{"kind": "INTERFACE", "name": "Node", "ofType": null}]},
+        {"kind": "INTERFACE", "name": "Node", "fields": [
+          {"name": "id", "args": [], "type": {"kind": "NON_NULL", "name": null, "ofType": {"kind": "SCALAR", "name": "ID", "ofType": null}}}
+        ], "possibleTypes": [{"kind": "OBJECT", "name": "User", "ofType": null}]},
+        {"kind": "ENUM", "name": "Role", "enumValues": [{"name": "ADMIN"}, {"name": "MEMBER"}]},
+        {"kind": "SCALAR", "name": "ID"},
+        {"kind": "SCALAR", "name": "Int"},
+        {"kind": "SCALAR", "name": "String"},
+        {"kind": "SCALAR", "name": "Boolean"}
+      ],
+      "directives": [
+        {"name": "include", "locations": ["FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"], "args": [
+          {"name": "if", "type": {"kind": "NON_NULL", "name": null, "ofType": {"kind": "SCALAR", "name": "Boolean", "ofType": null}}, "defaultValue": null}
+        ]}
+      ]
+    }
+  }
+}`
+
+type IntrospectedUserQuery struct {
+	User struct {
+		ID   string `json:"id" graphql:"id"`
+		Name string `json:"name" graphql:"name"`
+	} `json:"user" graphql:"user(id:$id:ID!)"`
+	Users []struct {
+		ID string `json:"id" graphql:"id"`
+	} `json:"users" graphql:"users(first:$first:String)"`
+}
+
+func TestParseIntrospection(t *testing.T) {
+	s, err := schema.ParseIntrospection([]byte(userIntrospection))
+	if err != nil {
+		t.Fatalf("ParseIntrospection failed: %v", err)
+	}
+	if s.QueryType != "Query" || s.MutationType != "" {
+		t.Errorf("unexpected root types: %q %q", s.QueryType, s.MutationType)
+	}
+	users := s.Type("Query").Field("users")
+	if users.Type.String() != "[User!]!" || *users.Arg("first").DefaultValue != "10" {
+		t.Errorf("unexpected users field: %s", users.Type)
+	}
+	if s.Type("User").Description != "A user" || !s.Type("User").Implements("Node") {
+		t.Errorf("unexpected User type: %+v", s.Type("User"))
+	}
+	if got := s.PossibleTypes(s.Type("Node")); len(got) != 1 || got[0] != "User" {
+		t.Errorf("unexpected possible types: %v", got)
+	}
+	// 内省结果中缺失的内置标量与指令会被补全
+	if s.Type("Float") == nil || s.Directives["skip"] == nil {
+		t.Error("built-ins missing")
+	}
+
+	exec, err := graphql.Marshal(IntrospectedUserQuery{})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	err = s.Validate(exec, "query")
+	if err == nil || !strings.Contains(err.Error(), `IntrospectedUserQuery.Users: argument "first" on field "users": variable of type "String" is not compatible with "Int"`) {
+		t.Errorf("unexpected validation result: %v", err)
+	}
+
+	// 也接受不含 data 包装的快照
+	snapshot := strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(userIntrospection), `{
+  "data": `), "\n}")
+	if _, err := schema.ParseIntrospection([]byte(snapshot)); err != nil {
+		t.Errorf("ParseIntrospection without data wrapper failed: %v", err)
+	}
+	if _, err := schema.ParseIntrospection([]byte(`{"data":{}}`)); err == nil {
+		t.Error("expected error for missing __schema")
+	}
+}
+
+func TestIntrospectionQuery(t *testing.T) {
+	query, err := schema.IntrospectionQuery()
+	if err != nil {
+		t.Fatalf("IntrospectionQuery failed: %v", err)
+	}
+	for _, want := range []string{
+		"query IntrospectionQuery {",
+		"fragment Schema__Type on __Type{",
+		"fragment Schema__InputValue on __InputValue{",
+		"fields(includeDeprecated:true){",
+		"enumValues(includeDeprecated:true){",
+		"args{ ...Schema__InputValue }",
+		"possibleTypes{ ...Schema__Type }",
+		"isRepeatable",
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("missing %q in:\n%s", want, query)
+		}
+	}
+	// 只有 __Type 与 __InputValue 生成 Fragment
+	if got := strings.Count(query, "fragment "); got != 2 {
+		t.Errorf("got %d fragments, want 2:\n%s", got, query)
+	}
+	if got := strings.Count(query, "ofType"); got != 7 {
+		t.Errorf("got %d ofType levels, want 7", got)
+	}
+	if _, err := lexer.Tokenize(query); err != nil {
+		t.Errorf("introspection query is not lexically valid: %v", err)
+	}
+}
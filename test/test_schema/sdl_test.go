@@ -0,0 +1,190 @@
+package test_schema
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lascyb/struct-to-graphql/internal/lexer"
+	"github.com/lascyb/struct-to-graphql/schema"
+)
+
+// 测试 SDL 解析
+const shopSDL = `
+"""
+商品查询入口
+"""
+schema {
+  query: QueryRoot
+  mutation: Mutation
+}
+
+directive @cached(ttl: Int!, scope: String) on FIELD | FRAGMENT_SPREAD | INLINE_FRAGMENT
+
+scalar URL @specifiedBy(url: "https://tools.ietf.org/html/rfc3986")
+
+enum ProductSortKeys {
+  TITLE
+  "按价格排序"
+  PRICE
+  ID @deprecated
+}
+
+interface Node {
+  id: ID!
+}
+
+type Product implements Node {
+  id: ID!
+  title: String!
+  price(currency: String = "USD"): Float
+  tags(first: Int!): [String!]!
+  images(first: Int = 10, sortKey: ProductSortKeys): [Image!]!
+}
+
+type Image implements Node {
+  id: ID!
+  url(width: Int, height: Int): URL!
+}
+
+type Collection implements Node {
+  id: ID!
+  title: String!
+}
+
+union SearchResult = | Product | Collection
+
+input ProductInput {
+  id: ID
+  title: String!
+  tags: [String!] = []
+}
+
+type QueryRoot {
+  product(id: ID!): Product
+  products(first: Int, query: String): [Product!]!
+  node(id: ID!): Node
+  search(query: String!): [SearchResult!]!
+}
+
+type Mutation {
+  productUpdate(input: ProductInput!): Product
+}
+
+extend type QueryRoot {
+  collection(id: ID!): Collection @deprecated(reason: "use node")
+}
+`
+
+func TestParseSDL(t *testing.T) {
+	s, err := schema.Parse(shopSDL)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if s.QueryType != "QueryRoot" || s.MutationType != "Mutation" || s.SubscriptionType != "" {
+		t.Errorf("unexpected root types: %q %q %q", s.QueryType, s.MutationType, s.SubscriptionType)
+	}
+	for _, name := range []string{"Int", "Float", "String", "Boolean", "ID"} {
+		if typ := s.Type(name); typ == nil || typ.Kind != schema.Scalar {
+			t.Errorf("built-in scalar %s missing", name)
+		}
+	}
+	if s.Directives["include"] == nil || s.Directives["cached"] == nil {
+		t.Error("directives missing")
+	}
+
+	product := s.Type("Product")
+	if product.Kind != schema.Object || !product.Implements("Node") || len(product.Fields) != 5 {
+		t.Fatalf("unexpected Product: %+v", product)
+	}
+	price := product.Field("price")
+	if price.Type.String() != "Float" || *price.Arg("currency").DefaultValue != `"USD"` {
+		t.Errorf("unexpected price field: %s default %v", price.Type, price.Arg("currency").DefaultValue)
+	}
+	tags := product.Field("tags")
+	if tags.Type.String() != "[String!]!" || tags.Type.NamedType() != "String" || !tags.Type.IsNonNull() {
+		t.Errorf("unexpected tags type: %s", tags.Type)
+	}
+	if kind := product.Field("images").Arg("sortKey").Type.Kind; kind != schema.Enum {
+		t.Errorf("named type reference should resolve to its kind, got %s", kind)
+	}
+
+	if got := s.PossibleTypes(s.Type("SearchResult")); len(got) != 2 || got[0] != "Product" || got[1] != "Collection" {
+		t.Errorf("unexpected union members: %v", got)
+	}
+	if got := s.PossibleTypes(s.Type("Node")); len(got) != 3 {
+		t.Errorf("unexpected interface implementations: %v", got)
+	}
+	if got := s.Type("ProductSortKeys").EnumValues; len(got) != 3 || got[2] != "ID" {
+		t.Errorf("unexpected enum values: %v", got)
+	}
+	if s.Type("ProductInput").InputField("title").Type.String() != "String!" {
+		t.Error("unexpected input field")
+	}
+	// extend type 合并到已有类型
+	if s.Type("QueryRoot").Field("collection") == nil {
+		t.Error("extended field missing")
+	}
+}
+
+func TestParseSDLErrors(t *testing.T) {
+	for name, sdl := range map[string]string{
+		"unknown type":   "type Query { user: User }",
+		"duplicate type": "type Query { id: ID }\ntype Query { id: ID }",
+		"bad member":     "type Query { id: ID }\nunion U = String",
+		"no query root":  "type User { id: ID }",
+		"input output":   "type Query { user(input: Query): ID }",
+	} {
+		if _, err := schema.Parse(sdl); err == nil {
+			t.Errorf("%s: expected error, got nil", name)
+		}
+	}
+
+	_, err := schema.Parse("type Query {\n  id: ID\n  name String\n}")
+	var syntaxErr *schema.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Fatalf("expected *schema.SyntaxError, got %v", err)
+	}
+	if syntaxErr.Line != 3 || syntaxErr.Column != 8 {
+		t.Errorf("got position %d:%d, want 3:8 (%v)", syntaxErr.Line, syntaxErr.Column, err)
+	}
+}
+
+func TestParseTypeRef(t *testing.T) {
+	for _, s := range []string{"ID", "ID!", "[ID]", "[[Int!]]!"} {
+		ref, err := schema.ParseTypeRef(s)
+		if err != nil {
+			t.Fatalf("ParseTypeRef(%q) failed: %v", s, err)
+		}
+		if ref.String() != s {
+			t.Errorf("got %q, want %q", ref.String(), s)
+		}
+	}
+	if _, err := schema.ParseTypeRef("[ID"); err == nil {
+		t.Error("expected error for unterminated list type")
+	}
+}
+
+func TestLexerStrings(t *testing.T) {
+	tokens, err := lexer.Tokenize(`"a\"b\\cé\u{1F600}😀" """
+    first
+      second
+    \""" end
+  """`)
+	if err != nil {
+		t.Fatalf("Tokenize failed: %v", err)
+	}
+	if len(tokens) != 2 {
+		t.Fatalf("got %d tokens, want 2", len(tokens))
+	}
+	if tokens[0].Kind != lexer.String || tokens[0].Value != "a\"b\\cé😀😀" {
+		t.Errorf("unexpected string value: %q", tokens[0].Value)
+	}
+	if tokens[1].Kind != lexer.BlockString || tokens[1].Value != "first\n  second\n\"\"\" end" {
+		t.Errorf("unexpected block string value: %q", tokens[1].Value)
+	}
+	for _, src := range []string{`"unterminated`, `"bad \q escape"`, `"\uD83D"`, `01`, `1.`, `1e`, `12abc`} {
+		if _, err := lexer.Tokenize(src); err == nil {
+			t.Errorf("expected error for %s", src)
+		}
+	}
+}
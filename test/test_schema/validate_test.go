@@ -0,0 +1,246 @@
+package test_schema
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	graphql "github.com/lascyb/struct-to-graphql"
+	"github.com/lascyb/struct-to-graphql/schema"
+)
+
+// 测试按 schema 校验结构体生成的选择集
+type ValidImage struct {
+	ID  string `json:"id" graphql:"id"`
+	URL string `json:"url" graphql:"url(width:$width:Int)"`
+}
+
+type ValidProduct struct {
+	ID     string       `json:"id" graphql:"id"`
+	Title  string       `json:"title" graphql:"title,@cached(ttl:60)"`
+	Price  float64      `json:"price" graphql:"price(currency:\"EUR\")"`
+	Tags   []string     `json:"tags" graphql:"tags(first:$tagCount:Int!)"`
+	Images []ValidImage `json:"images" graphql:"images(first:$:Int=5)"`
+}
+
+type ValidSearchProduct struct {
+	Title string `graphql:"title"`
+}
+
+type ValidSearchCollection struct {
+	ID string `graphql:"id"`
+}
+
+type ValidSearchResult struct {
+	Typename              string `graphql:"__typename,union"`
+	ValidSearchProduct    `graphql:",type=Product"`
+	ValidSearchCollection `graphql:",type=Collection"`
+}
+
+type ValidProductQuery struct {
+	Product ValidProduct        `json:"product" graphql:"product(id:$id:ID!),@include(if:$withProduct:Boolean!)"`
+	Search  []ValidSearchResult `json:"search" graphql:"search(query:$query:String!)"`
+	Node    struct {
+		ID string `json:"id" graphql:"id"`
+	} `json:"node" graphql:"node(id:$id:ID!),alias=item"`
+}
+
+func TestValidateValidQuery(t *testing.T) {
+	s, err := schema.Parse(shopSDL)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	exec, err := graphql.Marshal(ValidProductQuery{})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if err := s.Validate(exec, "query"); err != nil {
+		t.Errorf("expected valid query, got:\n%v", err)
+	}
+	if err := s.Validate(exec, "subscription"); err == nil {
+		t.Error("expected error for undefined subscription root")
+	}
+	if err := s.ValidateType(nil, "query"); err == nil {
+		t.Error("expected error for nil type parser")
+	}
+}
+
+type InvalidImage struct {
+	URL struct {
+		Host string `graphql:"host"`
+	} `json:"url" graphql:"url"`
+}
+
+type InvalidSearchResult struct {
+	Typename     string `graphql:"__typename,union"`
+	InvalidImage `graphql:",type=Image"`
+}
+
+type InvalidProduct struct {
+	Name   string         `json:"name" graphql:"name"`
+	Price  float64        `json:"price" graphql:"price(currency:42)"`
+	Tags   []string       `json:"tags" graphql:"tags"`
+	Images []InvalidImage `json:"images" graphql:"images(first:$first:String)"`
+	Stock  int            `json:"stock" graphql:"title(limit:1)"`
+}
+
+type InvalidProductQuery struct {
+	Product    InvalidProduct        `json:"product" graphql:"product(id:$id:ID),@live"`
+	Products   string                `json:"products" graphql:"products(first:10)"`
+	Search     []InvalidSearchResult `json:"search" graphql:"search(query:\"shirt\")"`
+	Collection struct {
+		ID string `graphql:"id"`
+	} `json:"collection" graphql:"collection(id:$id:ID),@cached(ttl:$ttl:Int)"`
+}
+
+func TestValidateReportsGoFieldPaths(t *testing.T) {
+	s, err := schema.Parse(shopSDL)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	exec, err := graphql.Marshal(InvalidProductQuery{})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	err = s.Validate(exec, "query")
+	var errs schema.ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected schema.ValidationErrors, got %v", err)
+	}
+	t.Logf("Validation errors:\n%v", err)
+
+	want := []struct{ goPath, message string }{
+		{"InvalidProductQuery.Product", `variable of type "ID" is not compatible with "ID!"`},
+		{"InvalidProductQuery.Product", `unknown directive "@live"`},
+		{"InvalidProductQuery.Product.Name", `field "name" is not defined on type "Product"`},
+		{"InvalidProductQuery.Product.Price", `value 42 is not a valid "String"`},
+		{"InvalidProductQuery.Product.Tags", `missing required argument "first" of type "Int!"`},
+		{"InvalidProductQuery.Product.Images", `variable of type "String" is not compatible with "Int"`},
+		{"InvalidProductQuery.Product.Images.URL", `must not have a selection`},
+		{"InvalidProductQuery.Product.Stock", `unknown argument "limit" on field "title"`},
+		{"InvalidProductQuery.Products", `must have a selection of subfields`},
+		{"InvalidProductQuery.Search.InvalidImage", `type "SearchResult" can never be of type "Image"`},
+		{"InvalidProductQuery.Collection", `argument "ttl" on directive "@cached": variable of type "Int" is not compatible with "Int!"`},
+	}
+	if len(errs) != len(want)+1 {
+		t.Errorf("got %d errors, want %d", len(errs), len(want)+1)
+	}
+	for _, w := range want {
+		found := false
+		for _, e := range errs {
+			if e.GoPath == w.goPath && strings.Contains(e.Message, w.message) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("missing error %s: %s", w.goPath, w.message)
+		}
+	}
+
+	var single *schema.ValidationError
+	if !errors.As(err, &single) || single.GoPath == "" {
+		t.Errorf("expected *schema.ValidationError via errors.As, got %v", err)
+	}
+	for _, e := range errs {
+		if e.GoPath == "InvalidProductQuery.Product.Name" && e.Path != "product.name" {
+			t.Errorf("got GraphQL path %q, want product.name", e.Path)
+		}
+	}
+}
+
+type InvalidCompositeMutation struct {
+	Missing struct {
+		ID string `json:"id" graphql:"id"`
+	} `json:"missing" graphql:"productUpdate(input:{tags:[\"a\"]}),alias=missing"`
+	Unknown struct {
+		ID string `json:"id" graphql:"id"`
+	} `json:"unknown" graphql:"productUpdate(input:{title:\"t\",color:RED}),alias=unknown"`
+	Item struct {
+		ID string `json:"id" graphql:"id"`
+	} `json:"item" graphql:"productUpdate(input:{title:$title:String!,tags:[1]}),alias=item"`
+	List struct {
+		ID string `json:"id" graphql:"id"`
+	} `json:"list" graphql:"productUpdate(input:[{title:\"t\"}]),alias=list"`
+	Variable struct {
+		ID string `json:"id" graphql:"id"`
+	} `json:"variable" graphql:"productUpdate(input:{title:$:Int!}),alias=variable"`
+	Enum struct {
+		Images []struct {
+			ID string `json:"id" graphql:"id"`
+		} `json:"images" graphql:"images(sortKey:enum(COLOR))"`
+		Title struct {
+			ID string `json:"id" graphql:"id"`
+		} `json:"sorted" graphql:"images(sortKey:enum(TITLE)),alias=sorted"`
+	} `json:"enum" graphql:"productUpdate(input:{title:\"t\"}),alias=enum"`
+}
+
+func TestValidateCompositeLiterals(t *testing.T) {
+	s, err := schema.Parse(shopSDL)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	exec, err := graphql.Marshal(InvalidCompositeMutation{})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	err = s.Validate(exec, "mutation")
+	var errs schema.ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected schema.ValidationErrors, got %v", err)
+	}
+	want := []struct{ goPath, message string }{
+		{"InvalidCompositeMutation.Missing", `missing required field "title" of type "String!" on input type "ProductInput"`},
+		{"InvalidCompositeMutation.Unknown", `unknown field "color" on input type "ProductInput"`},
+		{"InvalidCompositeMutation.Item", `field "tags" of "ProductInput": value 1 is not a valid "String!"`},
+		{"InvalidCompositeMutation.List", `value [{title:"t"}] is not a valid "ProductInput!"`},
+		{"InvalidCompositeMutation.Variable", `field "title" of "ProductInput": variable of type "Int!" is not compatible with "String!"`},
+		{"InvalidCompositeMutation.Enum.Images", `enum "ProductSortKeys" has no value COLOR`},
+	}
+	if len(errs) != len(want) {
+		t.Errorf("got %d errors, want %d:\n%v", len(errs), len(want), err)
+	}
+	for i, w := range want {
+		if i < len(errs) && (errs[i].GoPath != w.goPath || !strings.Contains(errs[i].Message, w.message)) {
+			t.Errorf("error %d: got %s, want %s: %s", i, errs[i], w.goPath, w.message)
+		}
+	}
+}
+
+// 手工构造的 Schema 引用了未定义的类型，校验应报告错误而不是 panic
+type UnresolvedQuery struct {
+	Product struct {
+		ID string `json:"id" graphql:"id"`
+	} `json:"product" graphql:"product(filter:{sku:\"a\"})"`
+}
+
+func TestValidateUnresolvedSchema(t *testing.T) {
+	s := &schema.Schema{
+		QueryType: "Query",
+		Types: map[string]*schema.Type{
+			"Query": {Kind: schema.Object, Name: "Query", Fields: []*schema.Field{{
+				Name: "product",
+				Args: []*schema.InputValue{{Name: "filter", Type: &schema.TypeRef{Kind: schema.InputObject, Name: "ProductFilter"}}},
+				Type: &schema.TypeRef{Kind: schema.Object, Name: "Product"},
+			}}},
+		},
+	}
+	exec, err := graphql.Marshal(UnresolvedQuery{})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	err = s.Validate(exec, "query")
+	var errs schema.ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected schema.ValidationErrors, got %v", err)
+	}
+	want := []string{`unknown type "ProductFilter"`, `unknown type "Product" of field "product"`}
+	if len(errs) != len(want) {
+		t.Fatalf("got %d errors, want %d:\n%v", len(errs), len(want), err)
+	}
+	for i, w := range want {
+		if errs[i].GoPath != "UnresolvedQuery.Product" || !strings.Contains(errs[i].Message, w) {
+			t.Errorf("error %d: got %s, want %s", i, errs[i], w)
+		}
+	}
+}